@@ -0,0 +1,73 @@
+package md2pw
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// headingSlugs returns the GitHub-style slug of every heading in the document.
+// Repeated slugs get a numeric suffix as on GitHub (foo, foo-1, foo-2).
+func headingSlugs(doc ast.Node, source []byte) map[ast.Node]string {
+	slugs := make(map[ast.Node]string)
+	seen := make(map[string]int)
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		h, ok := node.(*ast.Heading)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+		slug := githubSlug(plainText(source, h))
+		if n := seen[slug]; n > 0 {
+			seen[slug]++
+			slug += "-" + strconv.Itoa(n)
+		} else {
+			seen[slug] = 1
+		}
+		slugs[h] = slug
+		return ast.WalkSkipChildren, nil
+	})
+	return slugs
+}
+
+// githubSlug lowercases the text, drops punctuation and replaces spaces with hyphens.
+func githubSlug(text string) string {
+	var b strings.Builder
+	for _, c := range strings.ToLower(strings.TrimSpace(text)) {
+		switch {
+		case c == ' ':
+			b.WriteRune('-')
+		case c == '-' || c == '_' || unicode.IsLetter(c) || unicode.IsNumber(c):
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// anchorID returns the PukiWiki anchor for a heading slug. Link fragments are
+// GitHub-style slugs too, so they resolve to the same anchor as their heading.
+func (r *nodeRenderer) anchorID(slug string) string {
+	if r.opts.HeadingAnchors == AnchorSlug && pageAnchorRe.MatchString(slug) {
+		return slug
+	}
+	// Same shape as the anchors PukiWiki fixes itself: a letter and 7 hex digits.
+	sum := sha1.Sum([]byte(slug))
+	return "a" + hex.EncodeToString(sum[:])[:7]
+}
+
+// fragmentAnchor converts a link fragment to a PukiWiki anchor. Without heading
+// anchors only fragments that already are valid anchors are kept. Otherwise the
+// fragment is normalised like a heading slug, so #Getting-Started finds the
+// heading "Getting Started" as GitHub does.
+func (r *nodeRenderer) fragmentAnchor(fragment string) string {
+	if fragment == "" {
+		return ""
+	}
+	if r.opts.HeadingAnchors == AnchorNone {
+		return pageAnchor(fragment)
+	}
+	return r.anchorID(githubSlug(fragment))
+}
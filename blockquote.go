@@ -0,0 +1,61 @@
+package md2pw
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/util"
+)
+
+// maxQuoteLevel is the deepest blockquote level PukiWiki supports.
+const maxQuoteLevel = 3
+
+func (r *nodeRenderer) renderBlockquote(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	level := quoteLevel(node)
+	if entering {
+		beginBlock(w, node)
+		// Lists and code blocks only belong to a quote that is already open,
+		// so open it with an empty line when the quote does not start with text.
+		if _, ok := node.FirstChild().(*ast.Paragraph); !ok {
+			_, _ = w.WriteString(strings.Repeat(">", level) + "\n")
+		}
+		return ast.WalkContinue, nil
+	}
+
+	// Close the quote explicitly so that following blocks are not swallowed.
+	if followedByOutput(node) {
+		_, _ = w.WriteString(strings.Repeat("<", level) + "\n")
+	}
+	return ast.WalkContinue, nil
+}
+
+// followedByOutput reports whether anything is written after the blockquote
+// before the end of the document or of the quote enclosing it, which closes
+// the nested quote itself.
+func followedByOutput(node ast.Node) bool {
+	for n := node; n != nil; n = n.Parent() {
+		if n != node {
+			if _, ok := n.(*ast.Blockquote); ok {
+				return false
+			}
+		}
+		for next := n.NextSibling(); next != nil; next = next.NextSibling() {
+			if !isEmptyBlock(next) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// quoteLevel returns the nesting level of the blockquote containing the node,
+// clamped to maxQuoteLevel.
+func quoteLevel(node ast.Node) int {
+	level := 0
+	for p := node; p != nil; p = p.Parent() {
+		if _, ok := p.(*ast.Blockquote); ok {
+			level++
+		}
+	}
+	return min(level, maxQuoteLevel)
+}
@@ -0,0 +1,45 @@
+package md2pw
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/util"
+)
+
+func (r *nodeRenderer) renderEmphasis(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	em := node.(*ast.Emphasis)
+
+	// Level 2 is bold (**text**), Level 1 is italic (*text*)
+	marker := "'''"
+	if em.Level == 2 {
+		marker = "''"
+	}
+	_, _ = w.WriteString(marker)
+	return ast.WalkContinue, nil
+}
+
+func (r *nodeRenderer) renderStrikethrough(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	_, _ = w.WriteString("%%")
+	return ast.WalkContinue, nil
+}
+
+// braceEscaper escapes the braces of inline code written as &code{...};.
+var braceEscaper = strings.NewReplacer("{", charRef('{'), "}", charRef('}'))
+
+func (r *nodeRenderer) renderCodeSpan(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	code := plainText(source, node)
+	switch r.opts.InlineCodeStyle {
+	case InlineCodePlugin:
+		// Any brace in the body would end or unbalance the plugin argument.
+		body := escapeInline(code, '{', '}', false)
+		body = braceEscaper.Replace(body)
+		_, _ = w.WriteString("&code{" + escapePipes(body, node) + "};")
+	default:
+		_, _ = w.WriteString("''" + escapePipes(escapeInline(code, '\'', '\'', false), node) + "''")
+	}
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,91 @@
+package md2pw
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+func (r *nodeRenderer) renderCodeBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	beginBlock(w, node)
+
+	switch r.opts.CodeBlockStyle {
+	case CodeBlockPre:
+		writeMultilinePlugin(w, source, node.Lines(), "#pre")
+	case CodeBlockPlugin:
+		name := "#code"
+		if lang := r.codeLanguage(node, source); lang != "" {
+			name += "(" + lang + ")"
+		}
+		writeMultilinePlugin(w, source, node.Lines(), name)
+	default:
+		writeLines(w, source, node.Lines(), "  ") // 2スペースプレフィックス
+	}
+	return ast.WalkContinue, nil
+}
+
+// writeMultilinePlugin writes the lines as the body of a multiline block plugin.
+// The body is enclosed with as many braces as needed not to be closed early,
+// PukiWiki ends the block at the first line containing the closing braces.
+func writeMultilinePlugin(w util.BufWriter, source []byte, lines *text.Segments, name string) {
+	braces := 2
+	for i := 0; i < lines.Len(); i++ {
+		seg := lines.At(i)
+		if run := longestRun(seg.Value(source), '}'); run >= braces {
+			braces = run + 1
+		}
+	}
+
+	_, _ = w.WriteString(name + strings.Repeat("{", braces) + "\n")
+	writeLines(w, source, lines, "")
+	_, _ = w.WriteString(strings.Repeat("}", braces) + "\n")
+}
+
+// codeLanguage returns the code plugin language for the fence info string.
+func (r *nodeRenderer) codeLanguage(node ast.Node, source []byte) string {
+	fcb, ok := node.(*ast.FencedCodeBlock)
+	if !ok {
+		return ""
+	}
+	lang := strings.ToLower(string(fcb.Language(source)))
+	if mapped, ok := r.opts.CodeLanguages[lang]; ok {
+		return mapped
+	}
+	return lang
+}
+
+// writeLines writes each line of the segments with the given prefix.
+func writeLines(w util.BufWriter, source []byte, lines *text.Segments, prefix string) {
+	for i := 0; i < lines.Len(); i++ {
+		seg := lines.At(i)
+		_, _ = w.WriteString(prefix)
+		_, _ = w.WriteString(trimTrailingNewline(string(seg.Value(source))))
+		_ = w.WriteByte('\n')
+	}
+}
+
+func trimTrailingNewline(s string) string {
+	if len(s) > 0 && s[len(s)-1] == '\n' {
+		return s[:len(s)-1]
+	}
+	return s
+}
+
+// longestRun returns the length of the longest run of c in the line.
+func longestRun(line []byte, c byte) int {
+	longest, run := 0, 0
+	for _, b := range line {
+		if b != c {
+			run = 0
+			continue
+		}
+		run++
+		longest = max(longest, run)
+	}
+	return longest
+}
@@ -0,0 +1,46 @@
+// Package md2pw converts Markdown to PukiWiki notation.
+package md2pw
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// Convert converts the Markdown source to PukiWiki notation.
+func Convert(markdown []byte, opts ...Option) (string, error) {
+	o := DefaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := o.validate(); err != nil {
+		return "", err
+	}
+
+	md := goldmark.New(
+		goldmark.WithExtensions(
+			extension.Table,
+			extension.Strikethrough,
+			extension.Linkify,
+			extension.TaskList,
+			extension.DefinitionList,
+			extension.Footnote,
+		),
+		goldmark.WithRenderer(newRenderer(o)),
+	)
+
+	var buf bytes.Buffer
+	if err := md.Convert(markdown, &buf); err != nil {
+		return "", fmt.Errorf("failed to render pukiwiki: %v", err)
+	}
+
+	// Keep the trailing newline only when the input has one.
+	output := strings.TrimRight(buf.String(), "\n")
+	if output != "" && bytes.HasSuffix(markdown, []byte("\n")) {
+		output += "\n"
+	}
+	return output, nil
+}
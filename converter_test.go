@@ -0,0 +1,1281 @@
+package md2pw
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		expected string
+	}{
+		{
+			name:     "H1",
+			input:    []byte("# Heading 1"),
+			expected: "* Heading 1",
+		},
+		{
+			name:     "H2",
+			input:    []byte("## Heading 2"),
+			expected: "** Heading 2",
+		},
+		{
+			name:     "H3",
+			input:    []byte("### Heading 3"),
+			expected: "*** Heading 3",
+		},
+		{
+			name:     "複数種類の Heading がある場合も全て変換される",
+			input:    []byte("# H1\n\n## H2\n\n### H3"),
+			expected: "* H1\n\n** H2\n\n*** H3",
+		},
+		{
+			name:     "H4 は変換されない",
+			input:    []byte("# H1\n\n#### H4\n\n## H2"),
+			expected: "* H1\n\n#### H4\n\n** H2",
+		},
+		{
+			name:     "内容がそのまま保たれる",
+			input:    []byte("# Title\n\nSome paragraph text.\n\n## Section\n\nPlain text."),
+			expected: "* Title\n\nSome paragraph text.\n\n** Section\n\nPlain text.",
+		},
+		// Unordered List のテストケース
+		{
+			name:     "基本的な unordered list",
+			input:    []byte("- item1\n- item2\n- item3"),
+			expected: "-item1\n-item2\n-item3",
+		},
+		{
+			name:     "ネストした unordered list",
+			input:    []byte("- item1\n  - nested1\n  - nested2\n- item2"),
+			expected: "-item1\n--nested1\n--nested2\n-item2",
+		},
+		{
+			name:     "3レベルのネスト unordered list",
+			input:    []byte("- level1\n  - level2\n    - level3"),
+			expected: "-level1\n--level2\n---level3",
+		},
+		// Ordered List のテストケース
+		{
+			name:     "基本的な ordered list",
+			input:    []byte("1. item1\n2. item2\n3. item3"),
+			expected: "+item1\n+item2\n+item3",
+		},
+		{
+			name:     "ネストした ordered list",
+			input:    []byte("1. item1\n   1. nested1\n   2. nested2\n2. item2"),
+			expected: "+item1\n++nested1\n++nested2\n+item2",
+		},
+		{
+			name:     "3レベルのネスト ordered list",
+			input:    []byte("1. level1\n   1. level2\n      1. level3"),
+			expected: "+level1\n++level2\n+++level3",
+		},
+		// Codeblock のテストケース
+		{
+			name:     "基本的なコードブロック",
+			input:    []byte("```\ncode\n```"),
+			expected: "  code",
+		},
+		{
+			name:     "言語指定付きコードブロック",
+			input:    []byte("```go\nfunc main() {}\n```"),
+			expected: "  func main() {}",
+		},
+		{
+			name:     "複数行コードブロック",
+			input:    []byte("```\nline1\nline2\nline3\n```"),
+			expected: "  line1\n  line2\n  line3",
+		},
+		{
+			name:     "インデント保持の確認",
+			input:    []byte("```\n  indented\n    more indented\n```"),
+			expected: "    indented\n      more indented",
+		},
+		{
+			name:     "見出しとコードブロックの混在",
+			input:    []byte("# Title\n\n```\ncode\n```\n\n## Section"),
+			expected: "* Title\n\n  code\n\n** Section",
+		},
+		{
+			name:     "リストとコードブロックの混在",
+			input:    []byte("- item1\n\n```\ncode\n```\n\n- item2"),
+			expected: "-item1\n\n  code\n\n-item2",
+		},
+		{
+			name:     "空のコードブロック",
+			input:    []byte("```\n```"),
+			expected: "",
+		},
+		// Bold のテストケース
+		{
+			name:     "基本的なBold変換",
+			input:    []byte("This is **bold** text"),
+			expected: "This is ''bold'' text",
+		},
+		{
+			name:     "複数のBoldが同一行にある場合",
+			input:    []byte("**first** and **second** bold"),
+			expected: "''first'' and ''second'' bold",
+		},
+		{
+			name:     "見出し内のBold",
+			input:    []byte("# Heading with **bold**"),
+			expected: "* Heading with ''bold''",
+		},
+		{
+			name:     "リスト内のBold",
+			input:    []byte("- item with **bold**\n- normal item"),
+			expected: "-item with ''bold''\n-normal item",
+		},
+		{
+			name:     "コードブロック内のBoldは変換しない",
+			input:    []byte("```\n**not bold**\n```"),
+			expected: "  **not bold**",
+		},
+		{
+			name:     "BoldとItalicが混在",
+			input:    []byte("**bold** and *italic*"),
+			expected: "''bold'' and '''italic'''",
+		},
+		// Italic・Strikethrough・Inline code のテストケース
+		{
+			name:     "基本的なItalic変換",
+			input:    []byte("This is *italic* and _also italic_ text"),
+			expected: "This is '''italic''' and '''also italic''' text",
+		},
+		{
+			name:     "基本的なStrikethrough変換",
+			input:    []byte("This is ~~deleted~~ text"),
+			expected: "This is %%deleted%% text",
+		},
+		{
+			name:     "基本的なInline code変換",
+			input:    []byte("Run `go test` now"),
+			expected: "Run ''go test'' now",
+		},
+		{
+			name:     "Inline code内のMarkdownは変換しない",
+			input:    []byte("`**not bold**`"),
+			expected: "''**not bold**''",
+		},
+		{
+			name:     "Bold内のItalicとStrikethrough",
+			input:    []byte("**bold *italic* ~~strike~~**"),
+			expected: "''bold '''italic''' %%strike%%''",
+		},
+		{
+			name:     "Link内のItalicとInline code",
+			input:    []byte("[*see* `code`](https://example.com)"),
+			expected: "[['''see''' ''code''>https://example.com]]",
+		},
+		{
+			name:     "リスト内のStrikethroughとInline code",
+			input:    []byte("- ~~done~~ `task`"),
+			expected: "-%%done%% ''task''",
+		},
+		{
+			name:     "テーブルセル内のItalicとStrikethrough",
+			input:    []byte("| A | B |\n| - | - |\n| *x* | ~~y~~ |"),
+			expected: "|~ A |~ B |\n| '''x''' | %%y%% |",
+		},
+		{
+			name:     "テーブルセル内のLinkとImageの | はエスケープされる",
+			input:    []byte("| [a](http://x\\|y) | ![p\\|q](a.png) |\n| - | - |\n| 1 | 2 |"),
+			expected: "|~ [[a>http://x%7Cy]] |~ &ref(a.png,p&#x7c;q); |\n| 1 | 2 |",
+		},
+		// Link のテストケース
+		{
+			name:     "基本的なLink変換",
+			input:    []byte("Click [here](https://example.com) for more"),
+			expected: "Click [[here>https://example.com]] for more",
+		},
+		{
+			name:     "複数のLinkが同一行にある場合",
+			input:    []byte("[first](https://a.com) and [second](https://b.com)"),
+			expected: "[[first>https://a.com]] and [[second>https://b.com]]",
+		},
+		{
+			name:     "見出し内のLink",
+			input:    []byte("# Heading with [link](https://example.com)"),
+			expected: "* Heading with [[link>https://example.com]]",
+		},
+		{
+			name:     "リスト内のLink",
+			input:    []byte("- item with [link](https://example.com)\n- normal item"),
+			expected: "-item with [[link>https://example.com]]\n-normal item",
+		},
+		{
+			name:     "参照形式のLink",
+			input:    []byte("[text][ref] and [ref]\n\n[ref]: https://example.com"),
+			expected: "[[text>https://example.com]] and [[ref>https://example.com]]",
+		},
+		{
+			name:     "参照定義の行は出力されない",
+			input:    []byte("[ref]: https://example.com\n\n[text][ref]\n\n[other]: https://example.org\n\nafter"),
+			expected: "[[text>https://example.com]]\n\nafter",
+		},
+		{
+			name:     "タイトル付きのLink",
+			input:    []byte("[text](https://example.com \"title\")"),
+			expected: "[[text>https://example.com]]",
+		},
+		{
+			name:     "Autolink",
+			input:    []byte("See <https://example.com> or <mail@example.com>"),
+			expected: "See https://example.com or mail@example.com",
+		},
+		{
+			name:     "URLそのままのURL",
+			input:    []byte("See https://example.com and www.example.com"),
+			expected: "See https://example.com and http://www.example.com",
+		},
+		{
+			name:     "テキストがURLと同じLink",
+			input:    []byte("[https://example.com](https://example.com)"),
+			expected: "https://example.com",
+		},
+		{
+			name:     "コードブロック内のLinkは変換しない",
+			input:    []byte("```\n[not link](https://example.com)\n```"),
+			expected: "  [not link](https://example.com)",
+		},
+		// Table のテストケース
+		{
+			name:     "基本的なテーブル変換",
+			input:    []byte("| Col1 | Col2 |\n| --- | --- |\n| A | B |"),
+			expected: "|~ Col1 |~ Col2 |\n| A | B |",
+		},
+		{
+			name:     "複数行のテーブル",
+			input:    []byte("| Column1 | Column2 | Column3 |\n| ------- | ------- | ------- |\n| Item1.1 | Item2.1 | Item3.1 |\n| Item1.2 | Item2.2 | Item3.2 |"),
+			expected: "|~ Column1 |~ Column2 |~ Column3 |\n| Item1.1 | Item2.1 | Item3.1 |\n| Item1.2 | Item2.2 | Item3.2 |",
+		},
+		{
+			name:     "見出しとテーブルの混在",
+			input:    []byte("# Title\n\n| A | B |\n| - | - |\n| 1 | 2 |"),
+			expected: "* Title\n\n|~ A |~ B |\n| 1 | 2 |",
+		},
+		{
+			name:     "リストとテーブルの混在",
+			input:    []byte("- item1\n\n| A | B |\n| - | - |\n| 1 | 2 |\n\n- item2"),
+			expected: "-item1\n\n|~ A |~ B |\n| 1 | 2 |\n\n-item2",
+		},
+		{
+			name:     "コードブロックとテーブルの混在",
+			input:    []byte("```\ncode\n```\n\n| A | B |\n| - | - |\n| 1 | 2 |"),
+			expected: "  code\n\n|~ A |~ B |\n| 1 | 2 |",
+		},
+		{
+			name:     "複数テーブル",
+			input:    []byte("| A | B |\n| - | - |\n| 1 | 2 |\n\n| X | Y |\n| - | - |\n| 3 | 4 |"),
+			expected: "|~ A |~ B |\n| 1 | 2 |\n\n|~ X |~ Y |\n| 3 | 4 |",
+		},
+		// Image のテストケース
+		{
+			name:     "段落に画像だけがある場合は #ref",
+			input:    []byte("![alt text](image.png)"),
+			expected: "#ref(image.png,alt text)",
+		},
+		{
+			name:     "文中の画像は &ref",
+			input:    []byte("See ![logo](logo.png) here"),
+			expected: "See &ref(logo.png,logo); here",
+		},
+		{
+			name:     "altが空の画像",
+			input:    []byte("![](image.png)"),
+			expected: "#ref(image.png)",
+		},
+		{
+			name:     "カンマを含むaltは引用される",
+			input:    []byte("![a, b](image.png)"),
+			expected: "#ref(image.png,\"a, b\")",
+		},
+		{
+			name:     "オプションと同じaltはオプションにならない",
+			input:    []byte("![center](a.png)\n\n![Wrap](b.png) ![50%](c.png) ![100x50](d.png) ![a);b](e.png)"),
+			expected: "#ref(a.png,,center)\n\n&ref(b.png,,Wrap); &ref(c.png,&#x35;0%); &ref(d.png,&#x31;00x50); &ref(e.png,a&#x29;;b);",
+		},
+		{
+			name:     "リスト内の画像は &ref",
+			input:    []byte("- ![icon](icon.png)"),
+			expected: "-&ref(icon.png,icon);",
+		},
+		{
+			name:     "Link内の画像",
+			input:    []byte("[![badge](badge.svg)](https://example.com)"),
+			expected: "[[&ref(badge.svg,badge);>https://example.com]]",
+		},
+		// Blockquote のテストケース
+		{
+			name:     "基本的な引用",
+			input:    []byte("> quoted\n> text"),
+			expected: "> quoted\ntext",
+		},
+		{
+			name:     "引用の後の段落は閉じてから出力される",
+			input:    []byte("> quoted\n\nafter"),
+			expected: "> quoted\n<\n\nafter",
+		},
+		{
+			name:     "ネストした引用",
+			input:    []byte("> level1\n> > level2\n> > > level3"),
+			expected: "> level1\n>> level2\n>>> level3",
+		},
+		{
+			name:     "4レベル以上の引用は3レベルにまとめられる",
+			input:    []byte("> > > > level4"),
+			expected: ">\n>>\n>>>\n>>> level4",
+		},
+		{
+			name:     "ネストした引用から外側の引用に戻る",
+			input:    []byte("> outer\n> > inner\n>\n> outer again"),
+			expected: "> outer\n>> inner\n<<\n> outer again",
+		},
+		{
+			name:     "リストを含む引用",
+			input:    []byte("> intro\n> - item1\n> - item2\n\nafter"),
+			expected: "> intro\n-item1\n-item2\n<\n\nafter",
+		},
+		{
+			name:     "リストで始まる引用",
+			input:    []byte("> - item1\n> - item2"),
+			expected: ">\n-item1\n-item2",
+		},
+		{
+			name:     "コードブロックを含む引用",
+			input:    []byte("> intro\n> ```\n> code\n> ```\n\n# Next"),
+			expected: "> intro\n  code\n<\n\n* Next",
+		},
+		{
+			name:     "引用内の段落は~で区切られる",
+			input:    []byte("> first\n>\n> second"),
+			expected: "> first\n> ~second",
+		},
+		{
+			name:     "リスト項目の最後の引用も閉じられる",
+			input:    []byte("- item\n  > quoted\n- next"),
+			expected: "-item\n> quoted\n<\n-next",
+		},
+		// エスケープのテストケース
+		{
+			name:     "行頭のブロック記号はエスケープされる",
+			input:    []byte("-5 degrees\n+1 vote\n:colon\n\\# hash\n\\> not quote\n,comma\n//comment"),
+			expected: "&#x2d;5 degrees\n&#x2b;1 vote\n&#x3a;colon\n&#x23; hash\n&#x3e; not quote\n&#x2c;comma\n&#x2f;/comment",
+		},
+		{
+			name:     "行頭以外のブロック記号はそのまま",
+			input:    []byte("a -5 b +1 c :d # e"),
+			expected: "a -5 b +1 c :d # e",
+		},
+		{
+			name:     "段落の2行目以降の行頭もエスケープされる",
+			input:    []byte("first\n\\* second"),
+			expected: "first\n&#x2a; second",
+		},
+		{
+			name:     "リスト項目の先頭のリスト記号はエスケープされる",
+			input:    []byte("- -5 degrees\n- :colon"),
+			expected: "-&#x2d;5 degrees\n-:colon",
+		},
+		{
+			name:     "行末のチルダはエスケープされる",
+			input:    []byte("ends with \\~"),
+			expected: "ends with &#x7e;",
+		},
+		{
+			name:     "インラインの記号はエスケープされる",
+			input:    []byte("\\[\\[page]] it''s 100%% ((note))"),
+			expected: "&#x5b;&#x5b;page&#x5d;&#x5d; it&#x27;&#x27;s 100&#x25;&#x25; &#x28;&#x28;note&#x29;&#x29;",
+		},
+		{
+			name:     "単独の記号はエスケープしない",
+			input:    []byte("it's [x] 100% (a)"),
+			expected: "it's [x] 100% (a)",
+		},
+		{
+			name:     "Boldに隣接する引用符はエスケープされる",
+			input:    []byte("**'quoted'**"),
+			expected: "''&#x27;quoted&#x27;''",
+		},
+		{
+			name:     "文字参照やプラグインになる&はエスケープされる",
+			input:    []byte("AT&T &amp;br; &ref(x);"),
+			expected: "AT&T &amp;br; &amp;ref(x);",
+		},
+		{
+			name:     "Link内の>はエスケープされる",
+			input:    []byte("[a > b](https://example.com)"),
+			expected: "[[a &gt; b>https://example.com]]",
+		},
+		{
+			name:     "Inline code内の記号はエスケープされる",
+			input:    []byte("`a''b`"),
+			expected: "''a&#x27;&#x27;b''",
+		},
+		{
+			name:     "テーブルセル先頭のチルダと書式指定はエスケープされる",
+			input:    []byte("| A | B |\n| - | - |\n| ~x | LEFT: y |"),
+			expected: "|~ A |~ B |\n| &#x7e;x | &#x4c;EFT: y |",
+		},
+		{
+			name:     "コードブロック内はエスケープしない",
+			input:    []byte("```\n-5 [[x]]\n```"),
+			expected: "  -5 [[x]]",
+		},
+		// ネスト・重複のテストケース
+		{
+			name:     "Link内のBold",
+			input:    []byte("[**bold link**](https://example.com)"),
+			expected: "[[''bold link''>https://example.com]]",
+		},
+		{
+			name:     "Bold内のLink",
+			input:    []byte("**see [here](https://example.com)**"),
+			expected: "''see [[here>https://example.com]]''",
+		},
+		{
+			name:     "Boldと同じ文字列が通常テキストにもある場合",
+			input:    []byte("word and **word**"),
+			expected: "word and ''word''",
+		},
+		{
+			name:     "テーブルセル内のBoldとLink",
+			input:    []byte("| A | B |\n| - | - |\n| **x** | [y](https://example.com) |"),
+			expected: "|~ A |~ B |\n| ''x'' | [[y>https://example.com]] |",
+		},
+		{
+			name:     "末尾の改行は保たれる",
+			input:    []byte("# Title\n"),
+			expected: "* Title\n",
+		},
+		{
+			name:     "< のセルは左のセルと結合する",
+			input:    []byte("| A | B | C |\n| - | - | - |\n| x | < | < |\n| y | z | < |"),
+			expected: "|~ A |~ B |~ C |\n|>|>| x |\n| y |>| z |",
+		},
+		{
+			name:     "^ のセルは上のセルと結合する",
+			input:    []byte("| A | B |\n| - | - |\n| x | y |\n| ^ | z |"),
+			expected: "|~ A |~ B |\n| x | y |\n|~| z |",
+		},
+		{
+			name:     "結合できない < と ^ はそのまま",
+			input:    []byte("| ^ | B |\n| - | - |\n| < | \\^ |"),
+			expected: "|~ ^ |~ B |\n| < | ^ |",
+		},
+		{
+			name:     "リスト項目の続きの段落は ~ で始める",
+			input:    []byte("- first\n\n  second\n\n  third\n- next"),
+			expected: "-first\n~second\n~third\n-next",
+		},
+		{
+			name:     "リスト項目内のコードブロック",
+			input:    []byte("- item\n\n  ```\n  code\n  ```\n\n  after\n- next"),
+			expected: "-item\n  code\n~after\n-next",
+		},
+		{
+			name:     "コードブロックから始まるリスト項目",
+			input:    []byte("-     code\n- next"),
+			expected: "-\n  code\n-next",
+		},
+		{
+			name:     "リスト項目内のテーブル",
+			input:    []byte("- item\n\n  | A |\n  | - |\n  | 1 |"),
+			expected: "-item\n|~ A |\n| 1 |",
+		},
+		{
+			name:     "リスト項目内の見出しは Bold になる",
+			input:    []byte("- # Title\n- item\n\n  ## Sub"),
+			expected: "-''Title''\n-item\n~''Sub''",
+		},
+		{
+			name:     "定義リスト",
+			input:    []byte("Apple\n: fruit"),
+			expected: ":Apple|fruit",
+		},
+		{
+			name:     "1つの用語に複数の説明",
+			input:    []byte("Apple\n: fruit\n: company"),
+			expected: ":Apple|fruit\n:|company",
+		},
+		{
+			name:     "複数の用語に1つの説明",
+			input:    []byte("Term1\nTerm2\n: shared"),
+			expected: ":Term1|\n:Term2|shared",
+		},
+		{
+			name:     "入れ子の定義リスト",
+			input:    []byte("Outer\n:   desc\n\n    Inner\n    :   inner desc"),
+			expected: ":Outer|desc\n::Inner|inner desc",
+		},
+		{
+			name:     "定義リストの説明の続きの段落",
+			input:    []byte("Term\n:   first\n\n    second"),
+			expected: ":Term|first\n~second",
+		},
+		{
+			name:     "用語の | はエスケープされる",
+			input:    []byte("a|b\n: desc"),
+			expected: ":a&#x7c;b|desc",
+		},
+		{
+			name:     "水平線は ---- になる",
+			input:    []byte("above\n\n---\n\n***\n\nbelow"),
+			expected: "above\n\n----\n\n----\n\nbelow",
+		},
+		{
+			name:     "強制改行は行末の ~ になる",
+			input:    []byte("line1  \nline2\\\nline3"),
+			expected: "line1~\nline2~\nline3",
+		},
+		{
+			name:     "インライン要素の後の強制改行",
+			input:    []byte("**bold**  \nnext"),
+			expected: "''bold''~\nnext",
+		},
+		{
+			name:     "強制改行の前の ~ はエスケープされる",
+			input:    []byte("tilde~  \nnext"),
+			expected: "tilde&#x7e;~\nnext",
+		},
+		{
+			name:     "リスト項目内の強制改行",
+			input:    []byte("- item  \n  more"),
+			expected: "-item~\nmore",
+		},
+		{
+			name:     "脚注内の強制改行は &br; になる",
+			input:    []byte("x[^1]\n\n[^1]: a  \n    b"),
+			expected: "x((a&br; b))",
+		},
+		{
+			name:     "Link内の改行は1行につなげる",
+			input:    []byte("[two\nwords](http://x.com)"),
+			expected: "[[two words>http://x.com]]",
+		},
+		{
+			name:     "Bold内の改行は1行につなげる",
+			input:    []byte("**bold\nacross**"),
+			expected: "''bold across''",
+		},
+		{
+			name:     "Strikethrough内の改行は1行につなげる",
+			input:    []byte("~~strike\nacross~~"),
+			expected: "%%strike across%%",
+		},
+		{
+			name:     "複数行のSetext見出しは1行になる",
+			input:    []byte("Heading line one\nline two\n==="),
+			expected: "* Heading line one line two",
+		},
+		{
+			name:     "脚注は (( )) になり定義は削除される",
+			input:    []byte("Text[^1].\n\n[^1]: A **bold** note\n\nAfter"),
+			expected: "Text((A ''bold'' note)).\n\nAfter",
+		},
+		{
+			name:     "複数行と複数段落の脚注は1行にまとめる",
+			input:    []byte("Text[^n]\n\n[^n]: line1\n    line2\n\n    second"),
+			expected: "Text((line1 line2 second))",
+		},
+		{
+			name:     "日本語の脚注の行はスペースなしでつなぐ",
+			input:    []byte("本文[^1]\n\n[^1]: 脚注の\n    続き"),
+			expected: "本文((脚注の続き))",
+		},
+		{
+			name:     "同じ脚注を何度も参照できる",
+			input:    []byte("a[^1] b[^1]\n\n[^1]: note"),
+			expected: "a((note)) b((note))",
+		},
+		{
+			name:     "脚注の前後の括弧はエスケープされる",
+			input:    []byte("(see[^1])\n\n[^1]: (x)"),
+			expected: "(see((&#x28;x&#x29;))&#x29;",
+		},
+		{
+			name:     "定義のない脚注はそのまま",
+			input:    []byte("text[^x]"),
+			expected: "text[^x]",
+		},
+		{
+			name:     "タスクリスト",
+			input:    []byte("- [ ] todo\n- [x] done\n  - [X] nested\n\n1. [ ] ordered"),
+			expected: "-☐ todo\n-☑ done\n--☑ nested\n\n+☐ ordered",
+		},
+		{
+			name:     "テキストのないタスク",
+			input:    []byte("- [ ]"),
+			expected: "-☐",
+		},
+		{
+			name:     "リスト項目の途中の [ ] はタスクではない",
+			input:    []byte("- item [ ] text"),
+			expected: "-item [ ] text",
+		},
+		{
+			name:     "セル内の | はエスケープされる",
+			input:    []byte("| A | B |\n| - | - |\n| a \\| b | `p \\| q` |"),
+			expected: "|~ A |~ B |\n| a &#x7c; b | ''p &#x7c; q'' |",
+		},
+		{
+			name:     "セル内の <br> は &br; になる",
+			input:    []byte("| A |\n| - |\n| line1<br>line2<br />line3 |"),
+			expected: "|~ A |\n| line1&br;line2&br;line3 |",
+		},
+		{
+			name:     "段落の <br> も &br; になる",
+			input:    []byte("line1<br>line2"),
+			expected: "line1&br;line2",
+		},
+		{
+			name:     "セル外の | はそのまま",
+			input:    []byte("a | b"),
+			expected: "a | b",
+		},
+		{
+			name:     "[[_TOC_]] は #contents になる",
+			input:    []byte("# Title\n\n[[_TOC_]]\n\n## A"),
+			expected: "* Title\n\n#contents\n\n** A",
+		},
+		{
+			name:     "<!-- toc --> は #contents になる",
+			input:    []byte("<!-- toc -->\n\n## A"),
+			expected: "#contents\n\n** A",
+		},
+		{
+			name:     "markdown-toc が生成した目次は #contents に置き換わる",
+			input:    []byte("<!-- toc -->\n\n- [A](#a)\n- [B](#b)\n\n<!-- tocstop -->\n\n## A\n\n## B"),
+			expected: "#contents\n\n** A\n\n** B",
+		},
+		{
+			name:     "文中の [[_TOC_]] は目次にならず通常のテキストとして変換される",
+			input:    []byte("see [[_TOC_]] here"),
+			expected: "see &#x5b;&#x5b;'''TOC'''&#x5d;&#x5d; here",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert returned error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestConvert_MixedLists(t *testing.T) {
+	// Every level uses the marker of its own list type.
+	tests := []struct {
+		name     string
+		input    []byte
+		expected string
+	}{
+		{
+			name:     "unordered > unordered > unordered",
+			input:    []byte("- level1\n  - level2\n    - level3"),
+			expected: "-level1\n--level2\n---level3",
+		},
+		{
+			name:     "unordered > unordered > ordered",
+			input:    []byte("- level1\n  - level2\n    1. level3"),
+			expected: "-level1\n--level2\n+++level3",
+		},
+		{
+			name:     "unordered > ordered > unordered",
+			input:    []byte("- level1\n  1. level2\n     - level3"),
+			expected: "-level1\n++level2\n---level3",
+		},
+		{
+			name:     "unordered > ordered > ordered",
+			input:    []byte("- level1\n  1. level2\n     1. level3"),
+			expected: "-level1\n++level2\n+++level3",
+		},
+		{
+			name:     "ordered > unordered > unordered",
+			input:    []byte("1. level1\n   - level2\n     - level3"),
+			expected: "+level1\n--level2\n---level3",
+		},
+		{
+			name:     "ordered > unordered > ordered",
+			input:    []byte("1. level1\n   - level2\n     1. level3"),
+			expected: "+level1\n--level2\n+++level3",
+		},
+		{
+			name:     "ordered > ordered > unordered",
+			input:    []byte("1. level1\n   1. level2\n      - level3"),
+			expected: "+level1\n++level2\n---level3",
+		},
+		{
+			name:     "ordered > ordered > ordered",
+			input:    []byte("1. level1\n   1. level2\n      1. level3"),
+			expected: "+level1\n++level2\n+++level3",
+		},
+		{
+			name:     "同じレベルで種類が変わる",
+			input:    []byte("1. ordered\n   - unordered\n\n   1. ordered again"),
+			expected: "+ordered\n--unordered\n++ordered again",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert returned error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestConvert_Options(t *testing.T) {
+	urlOnly := DefaultOptions()
+	urlOnly.LinkStyle = LinkURL
+
+	tests := []struct {
+		name     string
+		input    []byte
+		opts     []Option
+		expected string
+	}{
+		{
+			name:     "リストの深さを制限できる",
+			input:    []byte("- level1\n  - level2\n    - level3"),
+			opts:     []Option{WithListDepth(2)},
+			expected: "-level1\n--level2\n--level3",
+		},
+		{
+			name:     "ListIndent では深い項目を字下げする",
+			input:    []byte("- 1\n  - 2\n    - 3\n      - 4\n        - 5"),
+			opts:     []Option{WithListPolicy(ListIndent)},
+			expected: "-1\n--2\n---3\n---&#160;&#160;- 4\n---&#160;&#160;&#160;&#160;- 5",
+		},
+		{
+			name:     "ListDefinition では深い項目を定義リストにする",
+			input:    []byte("- 1\n  - 2\n    - 3\n      - 4\n        - 5\n- back"),
+			opts:     []Option{WithListPolicy(ListDefinition)},
+			expected: "-1\n--2\n---3\n:|4\n::|5\n-back",
+		},
+		{
+			name:     "ListDefinition はリストの深さの制限にも従う",
+			input:    []byte("- 1\n  - 2"),
+			opts:     []Option{WithListDepth(1), WithListPolicy(ListDefinition)},
+			expected: "-1\n::|2",
+		},
+		{
+			name:     "LinkURL ではURLのみ出力される",
+			input:    []byte("Click [here](https://example.com) for more"),
+			opts:     []Option{WithLinkStyle(LinkURL)},
+			expected: "Click https://example.com for more",
+		},
+		{
+			name:     "LinkURL でも相対パスの.mdへのLinkはページ名になる",
+			input:    []byte("[see setup](./setup.md#install) and [web](https://example.com/a.md)"),
+			opts:     []Option{WithLinkStyle(LinkURL), WithDocumentPath("guide/index.md"), WithPagePrefix("Docs")},
+			expected: "[[Docs/guide/setup#install]] and https://example.com/a.md",
+		},
+		{
+			name:     "LinkURL でも文書内へのLinkは括弧で囲む",
+			input:    []byte("[x](#foo) start"),
+			opts:     []Option{WithLinkStyle(LinkURL)},
+			expected: "[[#foo]] start",
+		},
+		{
+			name:     "WithOptions で全ての設定を指定できる",
+			input:    []byte("[link](https://example.com)"),
+			opts:     []Option{WithOptions(urlOnly)},
+			expected: "https://example.com",
+		},
+		{
+			name:     "HeadingClamp では H4 以下が *** になる",
+			input:    []byte("# H1\n\n#### H4\n\n###### H6"),
+			opts:     []Option{WithHeadingPolicy(HeadingClamp)},
+			expected: "* H1\n\n*** H4\n\n*** H6",
+		},
+		{
+			name:     "HeadingBold では H4 以下が太字の段落になる",
+			input:    []byte("### H3\n\n#### H4 with [link](https://example.com)"),
+			opts:     []Option{WithHeadingPolicy(HeadingBold)},
+			expected: "*** H3\n\n''H4 with [[link>https://example.com]]''",
+		},
+		{
+			name:     "HeadingShift では最も浅い見出しが * になる",
+			input:    []byte("## H2\n\n### H3\n\n#### H4"),
+			opts:     []Option{WithHeadingPolicy(HeadingShift)},
+			expected: "* H2\n\n** H3\n\n*** H4",
+		},
+		{
+			name:     "HeadingShift でも深すぎる見出しは *** になる",
+			input:    []byte("## H2\n\n###### H6"),
+			opts:     []Option{WithHeadingPolicy(HeadingShift)},
+			expected: "* H2\n\n*** H6",
+		},
+		{
+			name:     "HeadingError でも H3 までは変換される",
+			input:    []byte("# H1\n\n### H3"),
+			opts:     []Option{WithHeadingPolicy(HeadingError)},
+			expected: "* H1\n\n*** H3",
+		},
+		{
+			name:     "CodeBlockPre では #pre プラグインになる",
+			input:    []byte("```go\nfunc main() {\n}\n```"),
+			opts:     []Option{WithCodeBlockStyle(CodeBlockPre)},
+			expected: "#pre{{\nfunc main() {\n}\n}}",
+		},
+		{
+			name:     "CodeBlockPlugin では言語付きの #code プラグインになる",
+			input:    []byte("```go\nfunc main() {}\n```"),
+			opts:     []Option{WithCodeBlockStyle(CodeBlockPlugin)},
+			expected: "#code(go){{\nfunc main() {}\n}}",
+		},
+		{
+			name:     "CodeBlockPlugin で言語指定がない場合",
+			input:    []byte("```\ncode\n```\n\n    indented"),
+			opts:     []Option{WithCodeBlockStyle(CodeBlockPlugin)},
+			expected: "#code{{\ncode\n}}\n\n#code{{\nindented\n}}",
+		},
+		{
+			name:     "言語名は対応表で変換される",
+			input:    []byte("```js\nx\n```\n\n```Shell\ny\n```"),
+			opts:     []Option{WithCodeBlockStyle(CodeBlockPlugin)},
+			expected: "#code(javascript){{\nx\n}}\n\n#code(bash){{\ny\n}}",
+		},
+		{
+			name:     "言語名の対応表を追加できる",
+			input:    []byte("```golang\nx\n```\n\n```tf\ny\n```"),
+			opts:     []Option{WithCodeBlockStyle(CodeBlockPlugin), WithCodeLanguages(map[string]string{"tf": "hcl"})},
+			expected: "#code(go){{\nx\n}}\n\n#code(hcl){{\ny\n}}",
+		},
+		{
+			name:     "閉じ括弧を含むコードは括弧を増やして囲む",
+			input:    []byte("```\n}}\n```"),
+			opts:     []Option{WithCodeBlockStyle(CodeBlockPre)},
+			expected: "#pre{{{\n}}\n}}}",
+		},
+		{
+			name:     "行の途中の閉じ括弧も数えて囲む",
+			input:    []byte("```go\nfmt.Println(\"{{ .Name }}\")\nx := T{{1}}}\n```"),
+			opts:     []Option{WithCodeBlockStyle(CodeBlockPlugin)},
+			expected: "#code(go){{{{\nfmt.Println(\"{{ .Name }}\")\nx := T{{1}}}\n}}}}",
+		},
+		{
+			name:     "相対パスの.mdへのLinkはページ名になる",
+			input:    []byte("[see setup](./setup.md#install) [up](../README.md) [web](https://example.com/a.md) [file](./a.txt)"),
+			opts:     []Option{WithDocumentPath("guide/index.md")},
+			expected: "[[see setup>guide/setup#install]] [[up>README]] [[web>https://example.com/a.md]] [[file>./a.txt]]",
+		},
+		{
+			name:     "ページ名にプレフィックスを付けられる",
+			input:    []byte("[see setup](setup.md#install)"),
+			opts:     []Option{WithPagePrefix("Docs")},
+			expected: "[[see setup>Docs/setup#install]]",
+		},
+		{
+			name:     "PukiWikiのアンカーにできないフラグメントは落とす",
+			input:    []byte("[see setup](setup.md#インストール)"),
+			expected: "[[see setup>setup]]",
+		},
+		{
+			name:  "ページ名の付け方を指定できる",
+			input: []byte("[see setup](./setup.md)"),
+			opts: []Option{WithPageName(func(path string) string {
+				return "Docs/" + strings.ToUpper(path[:1]) + path[1:]
+			})},
+			expected: "[[see setup>Docs/Setup]]",
+		},
+		{
+			name:     "AnchorSlug では見出しにスラッグのアンカーが付く",
+			input:    []byte("# Getting Started\n\n## Getting Started\n\n## Hello, World!"),
+			opts:     []Option{WithHeadingAnchors(AnchorSlug)},
+			expected: "* Getting Started [#getting-started]\n\n** Getting Started [#getting-started-1]\n\n** Hello, World! [#hello-world]",
+		},
+		{
+			name:     "複数行の見出しのアンカーは見出しの行に付く",
+			input:    []byte("Heading line one\nline two\n==="),
+			opts:     []Option{WithHeadingAnchors(AnchorSlug)},
+			expected: "* Heading line one line two [#heading-line-one-line-two]",
+		},
+		{
+			name:     "AnchorSlug でもアンカーにできないスラッグはハッシュになる",
+			input:    []byte("# 日本語の見出し\n\n[jp](#日本語の見出し)"),
+			opts:     []Option{WithHeadingAnchors(AnchorSlug)},
+			expected: "* 日本語の見出し [#ac6c9dd6]\n\n[[jp>#ac6c9dd6]]",
+		},
+		{
+			name:     "AnchorHash では見出しにハッシュのアンカーが付く",
+			input:    []byte("# Getting Started\n\nSee [start](#getting-started)"),
+			opts:     []Option{WithHeadingAnchors(AnchorHash)},
+			expected: "* Getting Started [#a6b2d359]\n\nSee [[start>#a6b2d359]]",
+		},
+		{
+			name:     "別ページへのLinkのフラグメントも同じアンカーになる",
+			input:    []byte("[install](setup.md#getting-started)"),
+			opts:     []Option{WithHeadingAnchors(AnchorHash)},
+			expected: "[[install>setup#a6b2d359]]",
+		},
+		{
+			name:     "大文字を含むフラグメントも見出しと同じアンカーになる",
+			input:    []byte("# Getting Started\n\n[d](other.md#Getting-Started) [e](#Getting%20Started)"),
+			opts:     []Option{WithHeadingAnchors(AnchorHash)},
+			expected: "* Getting Started [#a6b2d359]\n\n[[d>other#a6b2d359]] [[e>#a6b2d359]]",
+		},
+		{
+			name:     "AnchorSlug でもフラグメントは正規化される",
+			input:    []byte("[d](other.md#Getting-Started)"),
+			opts:     []Option{WithHeadingAnchors(AnchorSlug)},
+			expected: "[[d>other#getting-started]]",
+		},
+		{
+			name:     "PukiWikiの見出しにならない見出しにはアンカーを付けない",
+			input:    []byte("#### Deep"),
+			opts:     []Option{WithHeadingAnchors(AnchorSlug)},
+			expected: "#### Deep",
+		},
+		{
+			name:     "アンカーなしでは文書内のLinkはそのまま",
+			input:    []byte("[start](#getting-started)"),
+			expected: "[[start>#getting-started]]",
+		},
+		{
+			name:     "列の揃えは各セルの書式になる",
+			input:    []byte("| A | B | C | D |\n| :- | :-: | -: | - |\n| w | x | y | z |"),
+			expected: "|LEFT:~ A |CENTER:~ B |RIGHT:~ C |~ D |\n|LEFT: w |CENTER: x |RIGHT: y | z |",
+		},
+		{
+			name:     "TableAlignFormat では列の揃えを書式指定行に書く",
+			input:    []byte("| A | B | C |\n| :- | :-: | - |\n| x | y | z |"),
+			opts:     []Option{WithTableAlign(TableAlignFormat)},
+			expected: "|LEFT:|CENTER:||c\n|~ A |~ B |~ C |\n| x | y | z |",
+		},
+		{
+			name:     "TableAlignNone では列の揃えを捨てる",
+			input:    []byte("| A | B |\n| :- | :-: |\n| x | y |"),
+			opts:     []Option{WithTableAlign(TableAlignNone)},
+			expected: "|~ A |~ B |\n| x | y |",
+		},
+		{
+			name:     "列の幅は書式指定行に書く",
+			input:    []byte("| A | B | C |\n| :- | :-: | - |\n| x | y | z |"),
+			opts:     []Option{WithTableAlign(TableAlignFormat), WithTableWidths(100, 0, 50)},
+			expected: "|LEFT:100|CENTER:|50|c\n|~ A |~ B |~ C |\n| x | y | z |",
+		},
+		{
+			name:     "セルの書式と列の幅を併用できる",
+			input:    []byte("| A | B |\n| :- | - |\n| x | y |"),
+			opts:     []Option{WithTableWidths(80)},
+			expected: "|80||c\n|LEFT:~ A |~ B |\n|LEFT: x | y |",
+		},
+		{
+			name:     "タスクの印を変えられる",
+			input:    []byte("- [ ] todo\n- [x] done"),
+			opts:     []Option{WithTaskMarks("&check(off);", "&check(on);")},
+			expected: "-&check(off); todo\n-&check(on); done",
+		},
+		{
+			name:     "InlineCodePlugin でもセル内の | はエスケープされる",
+			input:    []byte("| A |\n| - |\n| `a \\| b` |"),
+			opts:     []Option{WithInlineCodeStyle(InlineCodePlugin)},
+			expected: "|~ A |\n| &code{a &#x7c; b}; |",
+		},
+		{
+			name:     "JoinParagraphs では折り返した行をつなげる",
+			input:    []byte("This is a long\nparagraph with **bold\ntext**\nand `code`."),
+			opts:     []Option{WithJoinParagraphs(true)},
+			expected: "This is a long paragraph with ''bold text'' and ''code''.",
+		},
+		{
+			name:     "JoinParagraphs では CJK の間にスペースを入れない",
+			input:    []byte("日本語の文章は\n折り返しても\nつながる。\nEnglish\n混在、\n**強調**\nの後"),
+			opts:     []Option{WithJoinParagraphs(true)},
+			expected: "日本語の文章は折り返してもつながる。 English 混在、''強調''の後",
+		},
+		{
+			name:     "JoinParagraphs でもハングルはスペースでつなぐ",
+			input:    []byte("한국어\n문장"),
+			opts:     []Option{WithJoinParagraphs(true)},
+			expected: "한국어 문장",
+		},
+		{
+			name:     "JoinParagraphs でも強制改行は残る",
+			input:    []byte("- item\n  continued  \n  hard\n\n> quote\n> line"),
+			opts:     []Option{WithJoinParagraphs(true)},
+			expected: "-item continued~\nhard\n\n> quote line",
+		},
+		{
+			name:     "LineBreakBR では強制改行を &br; にする",
+			input:    []byte("line1  \nline2"),
+			opts:     []Option{WithLineBreakStyle(LineBreakBR)},
+			expected: "line1&br;\nline2",
+		},
+		{
+			name:     "最初の列を見出しの列にできる",
+			input:    []byte("| A | B |\n| - | - |\n| x | y |\n| ^ | z |"),
+			opts:     []Option{WithTableHeaderColumn(true)},
+			expected: "|~ A |~ B |\n|~ x | y |\n|~| z |",
+		},
+		{
+			name:     "見出しが閾値より多いと最初の見出しの後に #contents を入れる",
+			input:    []byte("# Title\n\nintro\n\n## A\n\n## B"),
+			opts:     []Option{WithAutoContents(2)},
+			expected: "* Title\n\n#contents\n\nintro\n\n** A\n\n** B",
+		},
+		{
+			name:     "見出しが閾値以下なら #contents を入れない",
+			input:    []byte("# Title\n\n## A"),
+			opts:     []Option{WithAutoContents(2)},
+			expected: "* Title\n\n** A",
+		},
+		{
+			name:     "目次のマーカーがあれば #contents を自動で入れない",
+			input:    []byte("# Title\n\n## A\n\n[[_TOC_]]\n\n## B"),
+			opts:     []Option{WithAutoContents(1)},
+			expected: "* Title\n\n** A\n\n#contents\n\n** B",
+		},
+		{
+			name:     "画像にパラメータを付けられる",
+			input:    []byte("![alt](image.png)"),
+			opts:     []Option{WithImageParams("center", "50%")},
+			expected: "#ref(image.png,center,50%,alt)",
+		},
+		{
+			name:     "ローカル画像は添付先ページのファイル名に書き換えられる",
+			input:    []byte("![alt](./images/image.png) and ![remote](https://example.com/r.png)"),
+			opts:     []Option{WithImagePage("Docs/Setup")},
+			expected: "&ref(Docs/Setup/image.png,alt); and &ref(https://example.com/r.png,remote);",
+		},
+		{
+			name:     "InlineCodePlugin では &code プラグインを使う",
+			input:    []byte("Run `go test` now"),
+			opts:     []Option{WithInlineCodeStyle(InlineCodePlugin)},
+			expected: "Run &code{go test}; now",
+		},
+		{
+			name:     "InlineCodePlugin では括弧がエスケープされる",
+			input:    []byte("`a}b` and `{`"),
+			opts:     []Option{WithInlineCodeStyle(InlineCodePlugin)},
+			expected: "&code{a&#x7d;b}; and &code{&#x7b;};",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Convert(tt.input, tt.opts...)
+			if err != nil {
+				t.Fatalf("Convert returned error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestConvert_HeadingError(t *testing.T) {
+	_, err := Convert([]byte("# H1\n\n#### H4"), WithHeadingPolicy(HeadingError))
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("expected error to contain the line number, got %q", err.Error())
+	}
+}
+
+func TestConvert_ListError(t *testing.T) {
+	_, err := Convert([]byte("- 1\n  - 2\n    - 3\n      - 4"), WithListPolicy(ListError))
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "line 4") {
+		t.Errorf("expected error to contain the line number, got %q", err.Error())
+	}
+}
+
+func TestConvert_ListClampWarnings(t *testing.T) {
+	var warnings []Warning
+	_, err := Convert([]byte("- 1\n  - 2\n    - 3\n      - 4\n        - 5\n      - 4"), WithWarningHandler(func(w Warning) {
+		warnings = append(warnings, w)
+	}))
+	if err != nil {
+		t.Fatalf("Convert returned error: %v", err)
+	}
+	var lines []int
+	for _, w := range warnings {
+		lines = append(lines, w.Line)
+	}
+	if fmt.Sprint(lines) != "[4 5 6]" {
+		t.Errorf("expected warnings on lines [4 5 6], got %v", warnings)
+	}
+}
+
+func TestConvert_ListDefinitionWarnings(t *testing.T) {
+	tests := []struct {
+		name     string
+		depth    int
+		expected string
+		lines    string
+	}{
+		{name: "3段目の下には入れられない", depth: 3, expected: "-1\n--2\n---3\n:|4", lines: "[4]"},
+		{name: "浅いリストの項目には入れられる", depth: 2, expected: "-1\n--2\n:::|3\n:::|4", lines: "[4]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var warnings []Warning
+			result, err := Convert([]byte("- 1\n  - 2\n    - 3\n      - 4"), WithListDepth(tt.depth), WithListPolicy(ListDefinition), WithWarningHandler(func(w Warning) {
+				warnings = append(warnings, w)
+			}))
+			if err != nil {
+				t.Fatalf("Convert returned error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+			var lines []int
+			for _, w := range warnings {
+				lines = append(lines, w.Line)
+			}
+			if fmt.Sprint(lines) != tt.lines {
+				t.Errorf("expected warnings on lines %s, got %v", tt.lines, warnings)
+			}
+		})
+	}
+}
+
+func TestConvert_Warnings(t *testing.T) {
+	input := []byte("- a\n  - child\n\n  back\n- # Title\n- b\n\n  ---")
+	var warnings []Warning
+	result, err := Convert(input, WithWarningHandler(func(w Warning) {
+		warnings = append(warnings, w)
+	}))
+	if err != nil {
+		t.Fatalf("Convert returned error: %v", err)
+	}
+	expected := "-a\n--child\n~back\n-''Title''\n-b"
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+
+	lines := []int{4, 5, 6}
+	if len(warnings) != len(lines) {
+		t.Fatalf("expected %d warnings, got %v", len(lines), warnings)
+	}
+	for i, line := range lines {
+		if warnings[i].Line != line {
+			t.Errorf("expected warning %d on line %d, got %v", i, line, warnings[i])
+		}
+	}
+}
+
+func TestConvert_FootnoteWarnings(t *testing.T) {
+	var warnings []Warning
+	result, err := Convert([]byte("a[^1] b[^1]\n\n[^1]: note\n\n        code"), WithWarningHandler(func(w Warning) {
+		warnings = append(warnings, w)
+	}))
+	if err != nil {
+		t.Fatalf("Convert returned error: %v", err)
+	}
+	if result != "a((note)) b((note))" {
+		t.Errorf("Expected %q, got %q", "a((note)) b((note))", result)
+	}
+	if len(warnings) != 1 || warnings[0].Line != 5 {
+		t.Errorf("expected one warning on line 5, got %v", warnings)
+	}
+}
+
+func TestConvert_MissingAnchorWarnings(t *testing.T) {
+	var warnings []Warning
+	input := []byte("# Title\n\n#### Deep\n\n[x](#deep) [y](#nope) [z](#title)")
+	result, err := Convert(input, WithHeadingAnchors(AnchorHash), WithHeadingPolicy(HeadingLiteral), WithWarningHandler(func(w Warning) {
+		warnings = append(warnings, w)
+	}))
+	if err != nil {
+		t.Fatalf("Convert returned error: %v", err)
+	}
+	expected := "* Title [#a3c6de1b]\n\n#### Deep\n\n[[x>#deep]] [[y>#nope]] [[z>#a3c6de1b]]"
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+	if len(warnings) != 2 || warnings[0].Line != 5 || warnings[1].Line != 5 {
+		t.Errorf("expected two warnings on line 5, got %v", warnings)
+	}
+}
+
+func TestConvert_FootnoteCycles(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		expected string
+		warnings int
+	}{
+		{
+			name:     "自分自身を参照する脚注",
+			input:    []byte("Text[^1].\n\n[^1]: See also[^1]."),
+			expected: "Text((See also.)).",
+			warnings: 1,
+		},
+		{
+			name:     "循環して参照する脚注",
+			input:    []byte("A[^a] B[^b]\n\n[^a]: to b[^b]\n[^b]: to a[^a]"),
+			expected: "A((to b((to a)))) B((to a((to b))))",
+			warnings: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var warnings []Warning
+			result, err := Convert(tt.input, WithWarningHandler(func(w Warning) {
+				warnings = append(warnings, w)
+			}))
+			if err != nil {
+				t.Fatalf("Convert returned error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+			if len(warnings) != tt.warnings {
+				t.Errorf("expected %d warnings, got %v", tt.warnings, warnings)
+			}
+		})
+	}
+}
+
+func TestConvert_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "リストの深さが0", opts: []Option{WithListDepth(0)}},
+		{name: "リストの深さが上限超え", opts: []Option{WithListDepth(4)}},
+		{name: "未知のリストポリシー", opts: []Option{WithListPolicy("unknown")}},
+		{name: "未知の見出しポリシー", opts: []Option{WithHeadingPolicy("unknown")}},
+		{name: "未知のアンカー形式", opts: []Option{WithHeadingAnchors("unknown")}},
+		{name: "未知の改行形式", opts: []Option{WithLineBreakStyle("unknown")}},
+		{name: "未知の表の揃え形式", opts: []Option{WithTableAlign("unknown")}},
+		{name: "表の列の幅が負", opts: []Option{WithTableWidths(-1)}},
+		{name: "#contents の閾値が負", opts: []Option{WithAutoContents(-1)}},
+		{name: "未知のコードブロック形式", opts: []Option{WithCodeBlockStyle("unknown")}},
+		{name: "未知のリンク形式", opts: []Option{WithLinkStyle("unknown")}},
+		{name: "未知のInline code形式", opts: []Option{WithInlineCodeStyle("unknown")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Convert([]byte("# Title"), tt.opts...); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package md2pw
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The corpus under testdata/corpus holds pairs of NAME.md and NAME.pukiwiki
+// that both directions of the conversion are checked against.
+func loadCorpus(t *testing.T) map[string][2][]byte {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join("testdata", "corpus", "*.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	corpus := make(map[string][2][]byte)
+	for _, path := range paths {
+		md, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pw, err := os.ReadFile(strings.TrimSuffix(path, ".md") + ".pukiwiki")
+		if err != nil {
+			t.Fatal(err)
+		}
+		corpus[strings.TrimSuffix(filepath.Base(path), ".md")] = [2][]byte{md, pw}
+	}
+	if len(corpus) == 0 {
+		t.Fatal("corpus is empty")
+	}
+	return corpus
+}
+
+func TestCorpus_Convert(t *testing.T) {
+	for name, pair := range loadCorpus(t) {
+		t.Run(name, func(t *testing.T) {
+			result, err := Convert(pair[0])
+			if err != nil {
+				t.Fatalf("Convert returned error: %v", err)
+			}
+			if result != string(pair[1]) {
+				t.Errorf("Expected %q, got %q", string(pair[1]), result)
+			}
+		})
+	}
+}
+
+func TestCorpus_RoundTrip(t *testing.T) {
+	for name, pair := range loadCorpus(t) {
+		t.Run(name, func(t *testing.T) {
+			md, err := ToMarkdown(pair[1])
+			if err != nil {
+				t.Fatalf("ToMarkdown returned error: %v", err)
+			}
+			result, err := Convert([]byte(md))
+			if err != nil {
+				t.Fatalf("Convert returned error: %v", err)
+			}
+			if result != string(pair[1]) {
+				t.Errorf("Expected %q, got %q (markdown: %q)", string(pair[1]), result, md)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package md2pw
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+func (r *nodeRenderer) registerDefinitionFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(east.KindDefinitionList, r.renderDefinitionList)
+	reg.Register(east.KindDefinitionTerm, r.renderDefinitionTerm)
+	reg.Register(east.KindDefinitionDescription, r.renderDefinitionDescription)
+}
+
+func (r *nodeRenderer) renderDefinitionList(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		beginBlock(w, node)
+	}
+	return ast.WalkContinue, nil
+}
+
+// renderDefinitionTerm writes :term| and leaves the line open for the
+// description. Terms without a description of their own end the line.
+func (r *nodeRenderer) renderDefinitionTerm(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		_, _ = w.WriteString(definitionMarker(node))
+		return ast.WalkContinue, nil
+	}
+	_ = w.WriteByte('|')
+	if _, ok := node.NextSibling().(*east.DefinitionDescription); !ok {
+		_ = w.WriteByte('\n')
+	}
+	return ast.WalkContinue, nil
+}
+
+// renderDefinitionDescription writes the description after its term. Further
+// descriptions of the same term get an empty term (:|description).
+func (r *nodeRenderer) renderDefinitionDescription(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		if node.FirstChild() == nil {
+			_ = w.WriteByte('\n')
+		}
+		return ast.WalkContinue, nil
+	}
+	if _, ok := node.PreviousSibling().(*east.DefinitionTerm); !ok {
+		_, _ = w.WriteString(definitionMarker(node) + "|")
+	}
+	// Description text is written on the term line, anything else starts below it.
+	switch node.FirstChild().(type) {
+	case *ast.TextBlock, *ast.Paragraph, nil:
+	default:
+		_ = w.WriteByte('\n')
+	}
+	return ast.WalkContinue, nil
+}
+
+// definitionMarker returns the colons of the node's definition list level.
+func definitionMarker(node ast.Node) string {
+	level := 0
+	for p := node.Parent(); p != nil; p = p.Parent() {
+		if _, ok := p.(*east.DefinitionList); ok {
+			level++
+		}
+	}
+	return strings.Repeat(":", min(level, maxDefinitionLevel))
+}
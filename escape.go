@@ -0,0 +1,274 @@
+package md2pw
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/util"
+)
+
+// lineStart tells which PukiWiki syntax applies to the beginning of a text.
+type lineStart int
+
+const (
+	notLineStart   lineStart = iota
+	blockLineStart           // start of a line, every block syntax applies
+	listItemStart            // right after a list marker
+	quoteStart               // right after a quote marker
+	cellStart                // start of a table cell
+)
+
+const (
+	// blockChars start block syntax such as lists, headings, tables or quotes.
+	blockChars = "-+*|:><,#~ \t"
+	// pairChars start inline syntax when doubled, e.g. [[link]], ''bold'' or ((note)).
+	pairChars = "[]'%()"
+)
+
+// cellFormatRe matches the cell formats PukiWiki reads at the start of a table cell.
+var cellFormatRe = regexp.MustCompile(`^(?:(?:LEFT|CENTER|RIGHT):|(?:BGCOLOR|COLOR|SIZE)\()`)
+
+// textValue returns the text as Markdown means it, with backslash escapes
+// and character references resolved.
+func textValue(n *ast.Text, source []byte) string {
+	v := n.Segment.Value(source)
+	if n.IsRaw() {
+		return string(v)
+	}
+	v = util.UnescapePunctuations(v)
+	v = util.ResolveNumericReferences(v)
+	v = util.ResolveEntityNames(v)
+	return string(v)
+}
+
+// escapeText neutralises the characters of a text node that PukiWiki would
+// read as markup in the node's position.
+func (r *nodeRenderer) escapeText(n *ast.Text, source []byte) string {
+	s := textValue(n, source)
+	if s == "" {
+		return s
+	}
+
+	s = escapeInline(s, r.prevOutputByte(n, source), r.nextOutputByte(n, source), hasAncestor[*ast.Link](n))
+
+	if escapeLineStart(s, textLineStart(n)) {
+		s = charRef(s[0]) + s[1:]
+	}
+	if strings.HasSuffix(s, "~") && isLineEnd(n) {
+		// A trailing ~ is a line break.
+		s = s[:len(s)-1] + charRef('~')
+	}
+	return escapePipes(s, n)
+}
+
+// escapePipes escapes the | that would end the table cell or the definition
+// term the node is in.
+func escapePipes(s string, n ast.Node) string {
+	if !hasAncestor[*east.TableCell](n) && !hasAncestor[*east.DefinitionTerm](n) {
+		return s
+	}
+	return strings.ReplaceAll(s, "|", charRef('|'))
+}
+
+// unescapeCellPipes removes the backslash GFM needs before a | in a table cell
+// from a raw value, such as a link destination, that goldmark keeps as written.
+func unescapeCellPipes(s string, n ast.Node) string {
+	if !hasAncestor[*east.TableCell](n) {
+		return s
+	}
+	return strings.ReplaceAll(s, `\|`, "|")
+}
+
+// escapeURLPipes percent-encodes the | of a URL that would end the table cell
+// or the definition term the node is in. A character reference would be kept
+// literally in the URL.
+func escapeURLPipes(s string, n ast.Node) string {
+	if !hasAncestor[*east.TableCell](n) && !hasAncestor[*east.DefinitionTerm](n) {
+		return s
+	}
+	return strings.ReplaceAll(s, "|", "%7C")
+}
+
+// escapeInline escapes inline syntax in s. prev and next are the bytes
+// written right before and after s, or 0 when they are unknown.
+func escapeInline(s string, prev, next byte, inLink bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		p, n := prev, next
+		if i > 0 {
+			p = s[i-1]
+		}
+		if i+1 < len(s) {
+			n = s[i+1]
+		}
+
+		switch {
+		case strings.IndexByte(pairChars, c) >= 0 && (p == c || n == c):
+			b.WriteString(charRef(c))
+		case c == '&' && isCharRefOrPlugin(s[i+1:], next):
+			b.WriteString("&amp;")
+		case c == '>' && inLink:
+			// > separates the alias from the destination.
+			b.WriteString("&gt;")
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// escapeLineStart reports whether the first byte of s has to be escaped.
+func escapeLineStart(s string, start lineStart) bool {
+	switch start {
+	case blockLineStart:
+		return strings.IndexByte(blockChars, s[0]) >= 0 || strings.HasPrefix(s, "//")
+	case listItemStart:
+		return strings.IndexByte("-+~", s[0]) >= 0
+	case quoteStart:
+		return s[0] == '~'
+	case cellStart:
+		return strings.IndexByte("~>", s[0]) >= 0 || cellFormatRe.MatchString(s)
+	}
+	return false
+}
+
+// isCharRefOrPlugin reports whether s, following an &, would be read as a
+// character reference or an inline plugin such as &br; or &ref(...);.
+// next is the byte written after s.
+func isCharRefOrPlugin(s string, next byte) bool {
+	i := 0
+	for i < len(s) && (s[i] == '#' || s[i] == '_' || isAlnum(s[i])) {
+		i++
+	}
+	if i == 0 {
+		return false
+	}
+	if i < len(s) {
+		return strings.IndexByte(";({", s[i]) >= 0
+	}
+	return strings.IndexByte(";({", next) >= 0
+}
+
+func isAlnum(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+func charRef(c byte) string {
+	return fmt.Sprintf("&#x%x;", c)
+}
+
+// textLineStart returns the syntax that applies to the beginning of the text.
+func textLineStart(n *ast.Text) lineStart {
+	if prev := n.PreviousSibling(); prev != nil {
+		if t, ok := prev.(*ast.Text); ok && (t.SoftLineBreak() || t.HardLineBreak()) {
+			return blockLineStart
+		}
+		return notLineStart
+	}
+
+	switch p := n.Parent().(type) {
+	case *ast.Paragraph, *ast.TextBlock:
+		switch p.Parent().(type) {
+		case *ast.ListItem:
+			if p.PreviousSibling() == nil {
+				return listItemStart
+			}
+		case *ast.Blockquote:
+			return quoteStart
+		}
+		return blockLineStart
+	case *east.TableCell:
+		return cellStart
+	}
+	return notLineStart
+}
+
+// isLineEnd reports whether the text is the last thing written on its line.
+func isLineEnd(n *ast.Text) bool {
+	if n.SoftLineBreak() || n.HardLineBreak() {
+		return true
+	}
+	if next := n.NextSibling(); next != nil {
+		// Breaks after inline markup are empty texts of their own.
+		t, ok := next.(*ast.Text)
+		return ok && t.Segment.Len() == 0 && (t.SoftLineBreak() || t.HardLineBreak())
+	}
+	switch n.Parent().(type) {
+	case *ast.Paragraph, *ast.TextBlock, *ast.Heading:
+		return true
+	}
+	return false
+}
+
+// prevOutputByte returns the byte written right before the text.
+func (r *nodeRenderer) prevOutputByte(n *ast.Text, source []byte) byte {
+	if prev := n.PreviousSibling(); prev != nil {
+		if t, ok := prev.(*ast.Text); ok && !t.SoftLineBreak() && !t.HardLineBreak() {
+			if v := textValue(t, source); v != "" {
+				return v[len(v)-1]
+			}
+		}
+		return r.markupByte(prev, false, false)
+	}
+	return r.markupByte(n.Parent(), true, false)
+}
+
+// nextOutputByte returns the byte written right after the text.
+func (r *nodeRenderer) nextOutputByte(n *ast.Text, source []byte) byte {
+	if n.SoftLineBreak() || n.HardLineBreak() {
+		return 0
+	}
+	if next := n.NextSibling(); next != nil {
+		if t, ok := next.(*ast.Text); ok {
+			if v := textValue(t, source); v != "" {
+				return v[0]
+			}
+		}
+		return r.markupByte(next, false, true)
+	}
+	return r.markupByte(n.Parent(), true, true)
+}
+
+// markupByte returns the byte of the markup written for the inline node that
+// is adjacent to a text. inside tells whether the text is a child of the node,
+// and before whether the text comes before the markup.
+func (r *nodeRenderer) markupByte(n ast.Node, inside, before bool) byte {
+	switch n.(type) {
+	case *ast.Emphasis:
+		return '\''
+	case *east.Strikethrough:
+		return '%'
+	case *ast.CodeSpan:
+		if r.opts.InlineCodeStyle == InlineCodeBold {
+			return '\''
+		}
+	case *east.FootnoteLink:
+		if before {
+			return '(' // ((note))
+		}
+		return ')'
+	case *ast.Link:
+		switch {
+		case inside && before:
+			return '>' // [[text>url]]
+		case !inside && !before:
+			return ']'
+		default:
+			return '['
+		}
+	}
+	return 0
+}
+
+func hasAncestor[T ast.Node](n ast.Node) bool {
+	for p := n.Parent(); p != nil; p = p.Parent() {
+		if _, ok := p.(T); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package md2pw
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+func (r *nodeRenderer) registerFootnoteFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(east.KindFootnoteLink, r.renderFootnoteLink)
+	reg.Register(east.KindFootnoteBacklink, r.renderFootnoteBacklink)
+	reg.Register(east.KindFootnoteList, r.renderFootnoteList)
+}
+
+// footnotes returns the footnote definitions of the document by their index.
+func footnotes(doc ast.Node) map[int]*east.Footnote {
+	notes := make(map[int]*east.Footnote)
+	for child := doc.FirstChild(); child != nil; child = child.NextSibling() {
+		if list, ok := child.(*east.FootnoteList); ok {
+			for fn := list.FirstChild(); fn != nil; fn = fn.NextSibling() {
+				if n, ok := fn.(*east.Footnote); ok {
+					notes[n.Index] = n
+				}
+			}
+		}
+	}
+	return notes
+}
+
+// renderFootnoteLink writes the footnote inline as ((note)).
+func (r *nodeRenderer) renderFootnoteLink(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	fn, ok := r.footnotes[node.(*east.FootnoteLink).Index]
+	if !ok {
+		return ast.WalkContinue, nil
+	}
+	if r.footnoteVisiting[fn] {
+		// A note cannot contain itself, the reference would recurse forever.
+		r.warn(source, node, "footnote referring to itself is dropped")
+		return ast.WalkContinue, nil
+	}
+	// Notes referenced more than once are rendered, and warned about, once.
+	// Notes nested in another one are not cached, the reference cut off from a
+	// cycle depends on where the rendering started.
+	nested := len(r.footnoteVisiting) > 0
+	note, ok := r.footnoteText[fn]
+	if !ok || nested {
+		r.footnoteVisiting[fn] = true
+		var err error
+		note, err = r.renderFootnote(fn, source)
+		delete(r.footnoteVisiting, fn)
+		if err != nil {
+			return ast.WalkStop, err
+		}
+		if !nested {
+			r.footnoteText[fn] = note
+		}
+	}
+	_, _ = w.WriteString("((" + note + "))")
+	return ast.WalkContinue, nil
+}
+
+// renderFootnote renders the paragraphs of the footnote as a single line.
+func (r *nodeRenderer) renderFootnote(fn *east.Footnote, source []byte) (string, error) {
+	if r.inline == nil {
+		r.inline = renderer.NewRenderer(renderer.WithNodeRenderers(util.Prioritized(r, 100)))
+	}
+
+	var paragraphs []string
+	for block := fn.FirstChild(); block != nil; block = block.NextSibling() {
+		switch block.(type) {
+		case *ast.Paragraph, *ast.TextBlock:
+		case *east.FootnoteBacklink:
+			continue
+		default:
+			r.warn(source, block, "footnote content other than paragraphs is dropped")
+			continue
+		}
+		var buf bytes.Buffer
+		for child := block.FirstChild(); child != nil; child = child.NextSibling() {
+			if err := r.inline.Render(&buf, source, child); err != nil {
+				return "", fmt.Errorf("failed to render footnote: %v", err)
+			}
+		}
+		paragraphs = append(paragraphs, strings.TrimSpace(strings.ReplaceAll(buf.String(), "\n", " ")))
+	}
+
+	note := strings.Join(paragraphs, " ")
+	// Parentheses next to (( and )) would change where the note ends. A note
+	// ending in )) ends with a nested note, literal )) is already escaped.
+	if strings.HasPrefix(note, "(") {
+		note = charRef('(') + note[1:]
+	}
+	if strings.HasSuffix(note, ")") && !strings.HasSuffix(note, "))") {
+		note = note[:len(note)-1] + charRef(')')
+	}
+	return note, nil
+}
+
+func (r *nodeRenderer) renderFootnoteBacklink(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	return ast.WalkContinue, nil
+}
+
+// renderFootnoteList drops the definitions, their content is written at the references.
+func (r *nodeRenderer) renderFootnoteList(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,99 @@
+package md2pw
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/util"
+)
+
+const maxHeadingLevel = 3
+
+func (r *nodeRenderer) renderHeading(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*ast.Heading)
+	if _, ok := n.Parent().(*ast.ListItem); ok {
+		// A heading line would end the list.
+		if entering {
+			r.warn(source, n, "heading inside a list item is converted to bold text")
+			r.beginItemBlock(w, source, n)
+		}
+		return r.renderBoldHeading(w, n, entering)
+	}
+	level := n.Level - r.headingShift
+
+	if level > maxHeadingLevel {
+		switch r.opts.HeadingPolicy {
+		case HeadingClamp, HeadingShift:
+			level = maxHeadingLevel
+		case HeadingBold:
+			return r.renderBoldHeading(w, n, entering)
+		case HeadingError:
+			return ast.WalkStop, fmt.Errorf("line %d: heading level %d is deeper than %d", nodeLine(n, source), n.Level, maxHeadingLevel)
+		}
+	}
+
+	if !entering {
+		if slug, ok := r.headingSlugs[n]; ok && level <= maxHeadingLevel {
+			_, _ = w.WriteString(" [#" + r.anchorID(slug) + "]")
+		}
+		_ = w.WriteByte('\n')
+		if n == r.contentsNode {
+			_ = w.WriteByte('\n')
+			writeContents(w)
+		}
+		return ast.WalkContinue, nil
+	}
+
+	beginBlock(w, n)
+	// PukiWiki has only three heading levels, deeper headings are left as is.
+	marker := strings.Repeat("#", level)
+	if level <= maxHeadingLevel {
+		marker = strings.Repeat("*", level)
+	}
+	_, _ = w.WriteString(marker + " ")
+	return ast.WalkContinue, nil
+}
+
+// isPageHeading reports whether the heading is written as a PukiWiki heading,
+// not as bold text or left as is.
+func (r *nodeRenderer) isPageHeading(n *ast.Heading) bool {
+	if _, ok := n.Parent().(*ast.ListItem); ok {
+		return false
+	}
+	if n.Level-r.headingShift <= maxHeadingLevel {
+		return true
+	}
+	return r.opts.HeadingPolicy == HeadingClamp || r.opts.HeadingPolicy == HeadingShift
+}
+
+func (r *nodeRenderer) renderBoldHeading(w util.BufWriter, n *ast.Heading, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		beginBlock(w, n)
+		_, _ = w.WriteString("''")
+	} else {
+		_, _ = w.WriteString("''\n")
+		if ast.Node(n) == r.contentsNode {
+			_ = w.WriteByte('\n')
+			writeContents(w)
+		}
+	}
+	return ast.WalkContinue, nil
+}
+
+// minHeadingLevel returns the shallowest heading level in the document.
+func minHeadingLevel(doc ast.Node) int {
+	level := 1
+	found := false
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if h, ok := node.(*ast.Heading); ok && entering {
+			if !found || h.Level < level {
+				level = h.Level
+			}
+			found = true
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return level
+}
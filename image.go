@@ -0,0 +1,90 @@
+package md2pw
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/util"
+)
+
+func (r *nodeRenderer) renderImage(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	img := node.(*ast.Image)
+
+	args := []string{escapeURLPipes(r.imageSource(unescapeCellPipes(string(img.Destination), img)), img)}
+	args = append(args, r.opts.ImageParams...)
+	if alt := plainText(source, img); alt != "" {
+		args = append(args, refTitle(unescapeCellPipes(alt, img))...)
+	}
+	for i, arg := range args {
+		args[i] = quotePluginArg(escapePipes(arg, img))
+	}
+
+	// An image alone in a top-level paragraph becomes the block plugin.
+	if isStandaloneImage(img) {
+		_, _ = w.WriteString("#ref(" + strings.Join(args, ",") + ")")
+	} else {
+		_, _ = w.WriteString("&ref(" + strings.Join(args, ",") + ");")
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+// refOptions are the options of the ref plugin. PukiWiki reads an argument
+// that an option starts with as the option, until an empty argument.
+var refOptions = []string{"left", "center", "right", "wrap", "nowrap", "around", "noicon", "noimg", "nolink", "zoom"}
+
+// refSizeRe matches the sizes the ref plugin reads from any argument.
+var refSizeRe = regexp.MustCompile(`^(?:\d+x\d+|[\d.]+%)$`)
+
+// refTitle returns the ref plugin arguments that make the alt text the title
+// of the image rather than one of its options.
+func refTitle(alt string) []string {
+	// ); ends an inline plugin.
+	alt = strings.ReplaceAll(alt, ")", charRef(')'))
+	if refSizeRe.MatchString(alt) {
+		return []string{charRef(alt[0]) + alt[1:]}
+	}
+	for _, opt := range refOptions {
+		if strings.HasPrefix(opt, strings.ToLower(alt)) {
+			return []string{"", alt}
+		}
+	}
+	return []string{alt}
+}
+
+// imageSource returns the file name for the ref plugin. Local paths are
+// rewritten to attachments of Options.ImagePage when it is set.
+func (r *nodeRenderer) imageSource(dest string) string {
+	if r.opts.ImagePage == "" {
+		return dest
+	}
+	u, err := url.Parse(dest)
+	if err != nil || u.Scheme != "" || u.Host != "" {
+		return dest
+	}
+	return r.opts.ImagePage + "/" + path.Base(u.Path)
+}
+
+func isStandaloneImage(img *ast.Image) bool {
+	p, ok := img.Parent().(*ast.Paragraph)
+	if !ok {
+		return false
+	}
+	if _, ok := p.Parent().(*ast.Document); !ok {
+		return false
+	}
+	return img.PreviousSibling() == nil && img.NextSibling() == nil
+}
+
+// quotePluginArg quotes a plugin argument that contains a comma or a quote.
+func quotePluginArg(arg string) string {
+	if !strings.ContainsAny(arg, `,"`) {
+		return arg
+	}
+	return `"` + strings.ReplaceAll(arg, `"`, `""`) + `"`
+}
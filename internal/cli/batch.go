@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/moriT958/md2pw"
+)
+
+type batchConfig struct {
+	inputDir  string
+	outputDir string
+	includes  globList
+	excludes  globList
+	workers   int
+
+	inputExt  string // extension of the files included by default
+	outputExt string
+	convert   func(src []byte, path string, warn func(md2pw.Warning)) (string, error)
+}
+
+// globList is a repeatable flag of glob patterns.
+type globList []string
+
+func (g *globList) String() string {
+	return strings.Join(*g, ",")
+}
+
+func (g *globList) Set(v string) error {
+	if _, err := filepath.Match(v, ""); err != nil {
+		return fmt.Errorf("invalid glob %q: %v", v, err)
+	}
+	*g = append(*g, v)
+	return nil
+}
+
+// match reports whether any pattern matches the slash separated relative
+// path or its base name.
+func (g globList) match(rel string) bool {
+	for _, pattern := range g {
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, filepath.Base(rel)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type batchResult struct {
+	input    string
+	output   string
+	warnings []md2pw.Warning
+	err      error
+}
+
+func (c *CLI) runBatch(cfg batchConfig) int {
+	if cfg.outputDir == "" {
+		_, _ = fmt.Fprintln(c.errStream, "Error: output directory (-o) required with -r")
+		return 1
+	}
+	if len(cfg.includes) == 0 {
+		cfg.includes = globList{"*" + cfg.inputExt}
+	}
+	if cfg.workers < 1 {
+		cfg.workers = 1
+	}
+
+	inputs, err := collectInputs(cfg)
+	if err != nil {
+		_, _ = fmt.Fprintf(c.errStream, "Error reading input directory: %v\n", err)
+		return 1
+	}
+
+	results := make([]batchResult, len(inputs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range cfg.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = convertFile(cfg, inputs[i])
+			}
+		}()
+	}
+	for i := range inputs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	failed := 0
+	for _, res := range results {
+		for _, w := range res.warnings {
+			_, _ = fmt.Fprintf(c.errStream, "WARN %s: %s\n", res.input, w)
+		}
+		if res.err != nil {
+			failed++
+			_, _ = fmt.Fprintf(c.errStream, "FAIL %s: %v\n", res.input, res.err)
+		} else {
+			_, _ = fmt.Fprintf(c.outStream, "OK   %s -> %s\n", res.input, res.output)
+		}
+	}
+	_, _ = fmt.Fprintf(c.outStream, "%d converted, %d failed\n", len(results)-failed, failed)
+
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// collectInputs returns the files under the input directory that match the
+// include patterns and none of the exclude patterns, relative to the directory.
+func collectInputs(cfg batchConfig) ([]string, error) {
+	var inputs []string
+	err := filepath.WalkDir(cfg.inputDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(cfg.inputDir, path)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %v", path, err)
+		}
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if cfg.excludes.match(rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() && cfg.includes.match(rel) {
+			inputs = append(inputs, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %v", cfg.inputDir, err)
+	}
+	if len(inputs) == 0 {
+		return nil, errors.New("no files matched")
+	}
+	return inputs, nil
+}
+
+func convertFile(cfg batchConfig, rel string) batchResult {
+	src := filepath.Join(cfg.inputDir, filepath.FromSlash(rel))
+	dst := filepath.Join(cfg.outputDir, filepath.FromSlash(strings.TrimSuffix(rel, filepath.Ext(rel))+cfg.outputExt))
+	res := batchResult{input: src, output: dst}
+
+	content, err := os.ReadFile(src)
+	if err != nil {
+		res.err = fmt.Errorf("failed to read: %v", err)
+		return res
+	}
+	result, err := cfg.convert(content, rel, func(w md2pw.Warning) {
+		res.warnings = append(res.warnings, w)
+	})
+	if err != nil {
+		res.err = fmt.Errorf("failed to convert: %v", err)
+		return res
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		res.err = fmt.Errorf("failed to create directory: %v", err)
+		return res
+	}
+	if err := os.WriteFile(dst, []byte(result), 0644); err != nil {
+		res.err = fmt.Errorf("failed to write: %v", err)
+		return res
+	}
+	return res
+}
@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 
-	"github.com/moriT958/md2pw/internal/converter"
+	"github.com/moriT958/md2pw"
 )
 
 type CLI struct {
@@ -34,13 +37,43 @@ func isStdinPiped() bool {
 
 func (c *CLI) Run(args []string) int {
 	var outputFile string
+	var heading string
+	var anchors string
+	var list string
+	var codeblock string
+	var pagePrefix string
+	var linebreak string
+	var joinParagraphs bool
+	var tableAlign string
+	var headerColumn bool
+	var taskMarks string
+	var toc int
+	var reverse bool
+	var batch batchConfig
 
 	flags := flag.NewFlagSet("md2pw", flag.ContinueOnError)
 	flags.SetOutput(c.errStream)
-	flags.StringVar(&outputFile, "o", "", "output file path (default: stdout)")
+	flags.StringVar(&outputFile, "o", "", "output file path (default: stdout), or output directory with -r")
+	flags.StringVar(&anchors, "anchors", string(md2pw.AnchorNone), "fixed anchors appended to headings: none, hash or slug")
+	flags.StringVar(&list, "list", string(md2pw.ListClamp), "policy for lists deeper than 3 levels: clamp, indent, definition or error")
+	flags.StringVar(&codeblock, "codeblock", string(md2pw.CodeBlockIndent), "code block style: indent, pre or code-plugin")
+	flags.StringVar(&linebreak, "linebreak", string(md2pw.LineBreakTilde), "hard line break style: tilde or br")
+	flags.BoolVar(&joinParagraphs, "join-paragraphs", false, "join the soft-wrapped lines of paragraphs into single lines")
+	flags.StringVar(&pagePrefix, "page-prefix", "", "prefix of the page names relative .md links are converted to, e.g. Docs")
+	flags.StringVar(&tableAlign, "table-align", string(md2pw.TableAlignCell), "table column alignment: cell, format or none")
+	flags.BoolVar(&headerColumn, "header-column", false, "make the first column of tables a header column")
+	flags.StringVar(&taskMarks, "task-marks", "☐,☑", "marks of unchecked and checked task list items, separated by a comma")
+	flags.IntVar(&toc, "toc", 0, "insert #contents after the first heading when there are more headings than this (0: never)")
+	flags.BoolVar(&reverse, "reverse", false, "convert PukiWiki to Markdown instead")
+	flags.StringVar(&batch.inputDir, "r", "", "convert every file under the directory recursively")
+	flags.Var(&batch.includes, "include", "glob of files to convert with -r, repeatable (default: *.md, or *.txt with -reverse)")
+	flags.Var(&batch.excludes, "exclude", "glob of files or directories to skip with -r, repeatable")
+	flags.IntVar(&batch.workers, "j", runtime.NumCPU(), "number of files converted concurrently with -r")
+	flags.StringVar(&heading, "heading", string(md2pw.HeadingLiteral), "policy for headings deeper than H3: literal, clamp, bold, shift or error")
 
 	flags.Usage = func() {
-		_, _ = fmt.Fprintf(c.errStream, "Usage: md2pw [options] [<file.md>|-]\n\n")
+		_, _ = fmt.Fprintf(c.errStream, "Usage: md2pw [options] [<file.md>|-]\n")
+		_, _ = fmt.Fprintf(c.errStream, "       md2pw [options] -r <dir> -o <outdir>\n\n")
 		_, _ = fmt.Fprintf(c.errStream, "Options:\n")
 		flags.PrintDefaults()
 	}
@@ -49,8 +82,48 @@ func (c *CLI) Run(args []string) int {
 		return 1
 	}
 
+	unchecked, checked, ok := strings.Cut(taskMarks, ",")
+	if !ok {
+		_, _ = fmt.Fprintf(c.errStream, "Error: -task-marks needs two marks separated by a comma: %q\n", taskMarks)
+		return 1
+	}
+
+	opts := []md2pw.Option{
+		md2pw.WithHeadingPolicy(md2pw.HeadingPolicy(heading)),
+		md2pw.WithHeadingAnchors(md2pw.AnchorStyle(anchors)),
+		md2pw.WithListPolicy(md2pw.ListPolicy(list)),
+		md2pw.WithCodeBlockStyle(md2pw.CodeBlockStyle(codeblock)),
+		md2pw.WithPagePrefix(pagePrefix),
+		md2pw.WithLineBreakStyle(md2pw.LineBreakStyle(linebreak)),
+		md2pw.WithJoinParagraphs(joinParagraphs),
+		md2pw.WithAutoContents(toc),
+		md2pw.WithTableAlign(md2pw.TableAlignStyle(tableAlign)),
+		md2pw.WithTableHeaderColumn(headerColumn),
+		md2pw.WithTaskMarks(unchecked, checked),
+	}
+	// path is the slash separated path of the document that links are resolved from.
+	// Warnings of the conversion are passed to warn.
+	convert := func(src []byte, path string, warn func(md2pw.Warning)) (string, error) {
+		return md2pw.Convert(src, append(opts, md2pw.WithDocumentPath(path), md2pw.WithWarningHandler(warn))...)
+	}
+	batch.inputExt, batch.outputExt = ".md", ".txt"
+	if reverse {
+		convert = func(src []byte, _ string, _ func(md2pw.Warning)) (string, error) {
+			return md2pw.ToMarkdown(src)
+		}
+		batch.inputExt, batch.outputExt = ".txt", ".md"
+	}
+
+	if batch.inputDir != "" {
+		batch.outputDir = outputFile
+		batch.convert = convert
+		return c.runBatch(batch)
+	}
+
 	var content []byte
+	var docPath string
 	var err error
+	name := "<stdin>"
 
 	if flags.NArg() >= 1 {
 		filename := flags.Arg(0)
@@ -60,6 +133,8 @@ func (c *CLI) Run(args []string) int {
 		} else {
 			// File argument
 			content, err = os.ReadFile(filename)
+			docPath = filepath.Base(filename)
+			name = filename
 		}
 	} else if isStdinPiped() {
 		// No argument but stdin is piped
@@ -76,7 +151,9 @@ func (c *CLI) Run(args []string) int {
 		return 1
 	}
 
-	result, err := converter.Convert(content)
+	result, err := convert(content, docPath, func(w md2pw.Warning) {
+		_, _ = fmt.Fprintf(c.errStream, "Warning: %s: %s\n", name, w)
+	})
 	if err != nil {
 		_, _ = fmt.Fprintf(c.errStream, "Error converting: %v\n", err)
 		return 1
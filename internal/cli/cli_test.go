@@ -141,6 +141,150 @@ func TestRun_OutputToFile(t *testing.T) {
 	}
 }
 
+func TestRun_HeadingFlag(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		expectedOutput string
+		expectedCode   int
+	}{
+		{
+			name:           "default keeps deep headings",
+			args:           []string{"md2pw", "-"},
+			expectedOutput: "** H2\n\n#### H4",
+			expectedCode:   0,
+		},
+		{
+			name:           "clamp",
+			args:           []string{"md2pw", "-heading", "clamp", "-"},
+			expectedOutput: "** H2\n\n*** H4",
+			expectedCode:   0,
+		},
+		{
+			name:           "shift",
+			args:           []string{"md2pw", "-heading", "shift", "-"},
+			expectedOutput: "* H2\n\n*** H4",
+			expectedCode:   0,
+		},
+		{
+			name:         "error",
+			args:         []string{"md2pw", "-heading", "error", "-"},
+			expectedCode: 1,
+		},
+		{
+			name:         "unknown policy",
+			args:         []string{"md2pw", "-heading", "unknown", "-"},
+			expectedCode: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inStream := strings.NewReader("## H2\n\n#### H4")
+			outStream := &bytes.Buffer{}
+			errStream := &bytes.Buffer{}
+
+			c := New(inStream, outStream, errStream)
+			code := c.Run(tt.args)
+
+			if code != tt.expectedCode {
+				t.Errorf("expected exit code %d, got %d. stderr: %s", tt.expectedCode, code, errStream.String())
+			}
+			if tt.expectedCode == 0 && outStream.String() != tt.expectedOutput {
+				t.Errorf("expected output %q, got %q", tt.expectedOutput, outStream.String())
+			}
+		})
+	}
+}
+
+func TestRun_CodeBlockFlag(t *testing.T) {
+	inStream := strings.NewReader("```go\nfunc main() {}\n```")
+	outStream := &bytes.Buffer{}
+	errStream := &bytes.Buffer{}
+
+	c := New(inStream, outStream, errStream)
+	code := c.Run([]string{"md2pw", "-codeblock", "code-plugin", "-"})
+
+	if code != 0 {
+		t.Errorf("expected exit code 0, got %d. stderr: %s", code, errStream.String())
+	}
+	expected := "#code(go){{\nfunc main() {}\n}}"
+	if outStream.String() != expected {
+		t.Errorf("expected output %q, got %q", expected, outStream.String())
+	}
+}
+
+func TestRun_TOCFlag(t *testing.T) {
+	inStream := strings.NewReader("# Title\n\n## A\n\n## B")
+	outStream := &bytes.Buffer{}
+	errStream := &bytes.Buffer{}
+
+	c := New(inStream, outStream, errStream)
+	code := c.Run([]string{"md2pw", "-toc", "2", "-"})
+
+	if code != 0 {
+		t.Errorf("expected exit code 0, got %d. stderr: %s", code, errStream.String())
+	}
+	expected := "* Title\n\n#contents\n\n** A\n\n** B"
+	if outStream.String() != expected {
+		t.Errorf("expected output %q, got %q", expected, outStream.String())
+	}
+}
+
+func TestRun_Warnings(t *testing.T) {
+	inStream := strings.NewReader("- # Title")
+	outStream := &bytes.Buffer{}
+	errStream := &bytes.Buffer{}
+
+	c := New(inStream, outStream, errStream)
+	code := c.Run([]string{"md2pw", "-"})
+
+	if code != 0 {
+		t.Errorf("expected exit code 0, got %d. stderr: %s", code, errStream.String())
+	}
+	if outStream.String() != "-''Title''" {
+		t.Errorf("expected output %q, got %q", "-''Title''", outStream.String())
+	}
+	expected := "Warning: <stdin>: line 1: heading inside a list item is converted to bold text\n"
+	if errStream.String() != expected {
+		t.Errorf("expected stderr %q, got %q", expected, errStream.String())
+	}
+}
+
+func TestRun_JoinParagraphsFlag(t *testing.T) {
+	inStream := strings.NewReader("wrapped\nline\n\n日本語の\n文章")
+	outStream := &bytes.Buffer{}
+	errStream := &bytes.Buffer{}
+
+	c := New(inStream, outStream, errStream)
+	code := c.Run([]string{"md2pw", "--join-paragraphs", "-"})
+
+	if code != 0 {
+		t.Errorf("expected exit code 0, got %d. stderr: %s", code, errStream.String())
+	}
+	expected := "wrapped line\n\n日本語の文章"
+	if outStream.String() != expected {
+		t.Errorf("expected output %q, got %q", expected, outStream.String())
+	}
+}
+
+func TestRun_Reverse(t *testing.T) {
+	inStream := strings.NewReader("* Title\n\n-item\n\n[[link>https://example.com]]")
+	outStream := &bytes.Buffer{}
+	errStream := &bytes.Buffer{}
+
+	c := New(inStream, outStream, errStream)
+	code := c.Run([]string{"md2pw", "-reverse", "-"})
+
+	if code != 0 {
+		t.Errorf("expected exit code 0, got %d. stderr: %s", code, errStream.String())
+	}
+	expected := "# Title\n\n- item\n\n[link](https://example.com)"
+	if outStream.String() != expected {
+		t.Errorf("expected output %q, got %q", expected, outStream.String())
+	}
+}
+
 func TestRun_ErrorCases(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -152,6 +296,11 @@ func TestRun_ErrorCases(t *testing.T) {
 			args:         []string{"md2pw", "/nonexistent/file.md"},
 			expectedCode: 1,
 		},
+		{
+			name:         "task marks without comma",
+			args:         []string{"md2pw", "-task-marks", "x", "-"},
+			expectedCode: 1,
+		},
 	}
 
 	for _, tt := range tests {
@@ -169,3 +318,161 @@ func TestRun_ErrorCases(t *testing.T) {
 		})
 	}
 }
+
+func TestRun_Batch(t *testing.T) {
+	inputDir := t.TempDir()
+	files := map[string]string{
+		"a.md":        "# A",
+		"sub/b.md":    "## B\n\n[a](../a.md)",
+		"sub/deep.md": "#### Deep",
+		"skip/c.md":   "# C",
+		"notes.txt":   "not markdown",
+	}
+	for name, content := range files {
+		path := filepath.Join(inputDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedFiles map[string]string
+		missingFiles  []string
+		summary       string
+		expectedCode  int
+	}{
+		{
+			name: "mirrors the directory structure",
+			args: []string{"md2pw", "-r", inputDir, "-exclude", "skip"},
+			expectedFiles: map[string]string{
+				"a.txt":        "* A",
+				"sub/b.txt":    "** B\n\n[[a>a]]",
+				"sub/deep.txt": "#### Deep",
+			},
+			missingFiles: []string{"skip/c.txt", "notes.txt"},
+			summary:      "3 converted, 0 failed",
+			expectedCode: 0,
+		},
+		{
+			name: "include pattern",
+			args: []string{"md2pw", "-r", inputDir, "-include", "sub/*.md", "-j", "1", "-page-prefix", "Docs"},
+			expectedFiles: map[string]string{
+				"sub/b.txt": "** B\n\n[[a>Docs/a]]",
+			},
+			missingFiles: []string{"a.txt", "skip/c.txt"},
+			summary:      "2 converted, 0 failed",
+			expectedCode: 0,
+		},
+		{
+			name: "reverse",
+			args: []string{"md2pw", "-r", inputDir, "-reverse", "-include", "notes.txt"},
+			expectedFiles: map[string]string{
+				"notes.md": "not markdown",
+			},
+			missingFiles: []string{"a.txt"},
+			summary:      "1 converted, 0 failed",
+			expectedCode: 0,
+		},
+		{
+			name: "failed files make a non-zero exit",
+			args: []string{"md2pw", "-r", inputDir, "-heading", "error"},
+			expectedFiles: map[string]string{
+				"a.txt":     "* A",
+				"sub/b.txt": "** B\n\n[[a>a]]",
+			},
+			missingFiles: []string{"sub/deep.txt"},
+			summary:      "3 converted, 1 failed",
+			expectedCode: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+			outStream := &bytes.Buffer{}
+			errStream := &bytes.Buffer{}
+
+			c := New(strings.NewReader(""), outStream, errStream)
+			code := c.Run(append(tt.args, "-o", outputDir))
+
+			if code != tt.expectedCode {
+				t.Errorf("expected exit code %d, got %d. stderr: %s", tt.expectedCode, code, errStream.String())
+			}
+			for name, expected := range tt.expectedFiles {
+				content, err := os.ReadFile(filepath.Join(outputDir, filepath.FromSlash(name)))
+				if err != nil {
+					t.Errorf("expected %s to be written: %v", name, err)
+					continue
+				}
+				if string(content) != expected {
+					t.Errorf("expected %s content %q, got %q", name, expected, string(content))
+				}
+			}
+			for _, name := range tt.missingFiles {
+				if _, err := os.Stat(filepath.Join(outputDir, filepath.FromSlash(name))); err == nil {
+					t.Errorf("expected %s not to be written", name)
+				}
+			}
+			if !strings.Contains(outStream.String(), tt.summary) {
+				t.Errorf("expected summary %q, got %q", tt.summary, outStream.String())
+			}
+		})
+	}
+}
+
+func TestRun_BatchWarnings(t *testing.T) {
+	inputDir := t.TempDir()
+	input := filepath.Join(inputDir, "a.md")
+	if err := os.WriteFile(input, []byte("- # Title"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outStream := &bytes.Buffer{}
+	errStream := &bytes.Buffer{}
+
+	c := New(strings.NewReader(""), outStream, errStream)
+	code := c.Run([]string{"md2pw", "-r", inputDir, "-o", t.TempDir()})
+
+	if code != 0 {
+		t.Errorf("expected exit code 0, got %d. stderr: %s", code, errStream.String())
+	}
+	expected := "WARN " + input + ": line 1: heading inside a list item is converted to bold text\n"
+	if errStream.String() != expected {
+		t.Errorf("expected stderr %q, got %q", expected, errStream.String())
+	}
+}
+
+func TestRun_BatchErrorCases(t *testing.T) {
+	inputDir := t.TempDir()
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "output directory required",
+			args: []string{"md2pw", "-r", inputDir},
+		},
+		{
+			name: "no files matched",
+			args: []string{"md2pw", "-r", inputDir, "-o", t.TempDir()},
+		},
+		{
+			name: "nonexistent directory",
+			args: []string{"md2pw", "-r", "/nonexistent/dir", "-o", t.TempDir()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{})
+			if code := c.Run(tt.args); code != 1 {
+				t.Errorf("expected exit code 1, got %d", code)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package md2pw
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// softBreak returns what replaces the soft line break after the text when
+// lines are joined: nothing between two CJK characters, which are written
+// without spaces, and a space otherwise.
+func softBreak(n *ast.Text, source []byte) string {
+	prev, _ := utf8.DecodeLastRuneInString(adjacentText(n, source, false))
+	next, _ := utf8.DecodeRuneInString(adjacentText(n, source, true))
+	if isCJK(prev) && isCJK(next) {
+		return ""
+	}
+	return " "
+}
+
+// adjacentText returns the nearest non-empty text written after, or before,
+// the text within its block, looking out of inline markup such as emphasis.
+func adjacentText(n *ast.Text, source []byte, after bool) string {
+	if !after {
+		if v := textValue(n, source); v != "" {
+			return v
+		}
+	}
+	step := ast.Node.PreviousSibling
+	if after {
+		step = ast.Node.NextSibling
+	}
+	for node := ast.Node(n); node != nil && node.Type() == ast.TypeInline; node = node.Parent() {
+		for s := step(node); s != nil; s = step(s) {
+			var v string
+			if t, ok := s.(*ast.Text); ok {
+				v = textValue(t, source)
+			} else {
+				v = plainText(source, s)
+			}
+			if v != "" {
+				return v
+			}
+		}
+	}
+	return ""
+}
+
+// isCJK reports whether the rune belongs to a script written without spaces
+// between words. Hangul is not one of them.
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) ||
+		0x3000 <= r && r <= 0x303f || // CJK symbols and punctuation
+		0xff00 <= r && r <= 0xffef // halfwidth and fullwidth forms
+}
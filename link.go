@@ -0,0 +1,117 @@
+package md2pw
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/util"
+)
+
+var pageAnchorRe = regexp.MustCompile(`^[A-Za-z0-9][\w-]*$`)
+
+func (r *nodeRenderer) renderLink(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	link := node.(*ast.Link)
+	destination := unescapeCellPipes(string(link.Destination), link)
+	dest, ok := r.resolveLink(destination)
+	if !ok && entering {
+		r.warn(source, link, "link to %s does not match any heading", destination)
+	}
+	dest = escapeURLPipes(dest, link)
+	// A link showing its own URL needs no alias, PukiWiki links bare URLs.
+	// Pages and anchors, resolved or not, are only linked in brackets.
+	if r.opts.LinkStyle == LinkURL || plainText(source, link) == string(link.Destination) {
+		if entering {
+			if dest == escapeURLPipes(destination, link) && !strings.HasPrefix(dest, "#") {
+				_, _ = w.WriteString(dest)
+			} else {
+				_, _ = w.WriteString("[[" + dest + "]]")
+			}
+		}
+		return ast.WalkSkipChildren, nil
+	}
+
+	if entering {
+		_, _ = w.WriteString("[[")
+	} else {
+		_, _ = w.WriteString(">" + dest + "]]")
+	}
+	return ast.WalkContinue, nil
+}
+
+// resolveLink converts a relative link to a Markdown file into the PukiWiki
+// page and anchor it is published as, and a link to a heading in the same
+// document into its anchor. Other destinations are kept. ok is false for a
+// link into the document that no heading anchor matches, which is kept too.
+func (r *nodeRenderer) resolveLink(dest string) (resolved string, ok bool) {
+	u, err := url.Parse(dest)
+	if err != nil || u.Scheme != "" || u.Host != "" {
+		return dest, true
+	}
+	if u.Path == "" && u.Fragment != "" && r.opts.HeadingAnchors != AnchorNone {
+		slug := githubSlug(u.Fragment)
+		for _, s := range r.headingSlugs {
+			if s == slug {
+				return "#" + r.anchorID(slug), true
+			}
+		}
+		return dest, false
+	}
+	if !isMarkdownPath(u.Path) {
+		return dest, true
+	}
+
+	p := u.Path
+	if !path.IsAbs(p) {
+		p = path.Join(path.Dir(r.opts.DocumentPath), p)
+	}
+	p = strings.TrimPrefix(path.Clean(p), "/")
+	for strings.HasPrefix(p, "../") {
+		p = strings.TrimPrefix(p, "../")
+	}
+	page := r.pageName(strings.TrimSuffix(p, path.Ext(p)))
+
+	if anchor := r.fragmentAnchor(u.Fragment); anchor != "" {
+		return page + "#" + anchor, true
+	}
+	return page, true
+}
+
+func (r *nodeRenderer) pageName(p string) string {
+	if r.opts.PageName != nil {
+		return r.opts.PageName(p)
+	}
+	if r.opts.PagePrefix == "" {
+		return p
+	}
+	return strings.TrimSuffix(r.opts.PagePrefix, "/") + "/" + p
+}
+
+// pageAnchor returns the fragment when it is usable as a PukiWiki anchor,
+// which has to start with an alphanumeric and contain only [A-Za-z0-9_-].
+func pageAnchor(fragment string) string {
+	if !pageAnchorRe.MatchString(fragment) {
+		return ""
+	}
+	return fragment
+}
+
+func isMarkdownPath(p string) bool {
+	switch strings.ToLower(path.Ext(p)) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
+
+// renderAutoLink writes <https://...> autolinks, <mail@example.com> and bare
+// URLs found by Linkify as bare URLs and addresses, which PukiWiki links by itself.
+func (r *nodeRenderer) renderAutoLink(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		link := node.(*ast.AutoLink)
+		_, _ = w.Write(link.URL(source))
+	}
+	return ast.WalkSkipChildren, nil
+}
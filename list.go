@@ -0,0 +1,120 @@
+package md2pw
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/util"
+)
+
+// maxDefinitionLevel is the deepest definition list level PukiWiki supports.
+const maxDefinitionLevel = 3
+
+func (r *nodeRenderer) renderList(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		beginBlock(w, node)
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *nodeRenderer) renderListItem(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	li := node.(*ast.ListItem)
+	if !entering {
+		if li.FirstChild() == nil {
+			_ = w.WriteByte('\n')
+		}
+		return ast.WalkContinue, nil
+	}
+
+	level := 0
+	for p := li.Parent(); p != nil; p = p.Parent() {
+		if _, ok := p.(*ast.List); ok {
+			level++
+		}
+	}
+	// Every level has its own list type, PukiWiki counts the marker of the item's own list.
+	isOrdered := li.Parent().(*ast.List).IsOrdered()
+
+	marker := strings.Repeat("-", min(level, r.opts.ListDepth))
+	if isOrdered {
+		marker = strings.Repeat("+", min(level, r.opts.ListDepth))
+	}
+	if deeper := level - r.opts.ListDepth; deeper > 0 {
+		switch r.opts.ListPolicy {
+		case ListClamp:
+			r.warn(source, li, "list level %d is clamped to %d", level, r.opts.ListDepth)
+		case ListIndent:
+			marker += strings.Repeat("&#160;&#160;", deeper) + "- "
+		case ListDefinition:
+			// PukiWiki only nests a definition list in an item of a lower level,
+			// below the deepest level it ends the list and is written after it.
+			defLevel := r.opts.ListDepth + deeper
+			switch {
+			case r.opts.ListDepth >= maxDefinitionLevel:
+				r.warn(source, li, "list level %d is converted to a definition list after the list", level)
+				defLevel = min(deeper, maxDefinitionLevel)
+			case defLevel > maxDefinitionLevel:
+				r.warn(source, li, "list level %d is clamped to definition list level %d", level, maxDefinitionLevel)
+				defLevel = maxDefinitionLevel
+			}
+			marker = strings.Repeat(":", defLevel) + "|"
+		case ListError:
+			return ast.WalkStop, fmt.Errorf("line %d: list level %d is deeper than %d", nodeLine(li, source), level, r.opts.ListDepth)
+		}
+	}
+	_, _ = w.WriteString(marker)
+
+	// Item text is written on the marker line, anything else starts below it.
+	switch li.FirstChild().(type) {
+	case *ast.TextBlock, *ast.Paragraph, *ast.Heading, nil:
+	default:
+		_ = w.WriteByte('\n')
+	}
+	return ast.WalkContinue, nil
+}
+
+// isItemBody reports whether the node holds the blocks of a list item or a
+// definition description, whose first paragraph follows the marker.
+func isItemBody(node ast.Node) bool {
+	switch node.(type) {
+	case *ast.ListItem, *east.DefinitionDescription:
+		return true
+	}
+	return false
+}
+
+// beginItemBlock writes what precedes a paragraph of a list item: nothing for
+// the first one, which follows the list marker, and ~ for the others, which
+// PukiWiki reads as a paragraph of the item.
+func (r *nodeRenderer) beginItemBlock(w util.BufWriter, source []byte, node ast.Node) {
+	prev := node.PreviousSibling()
+	for prev != nil && isEmptyBlock(prev) {
+		prev = prev.PreviousSibling()
+	}
+	if prev == nil {
+		return
+	}
+	switch prev.(type) {
+	case *ast.List, *east.DefinitionList:
+		r.warn(source, node, "text after a nested list continues the last nested item")
+	}
+	_ = w.WriteByte('~')
+}
+
+// renderTaskCheckBox writes the mark of a GFM task list item ([ ] or [x]).
+func (r *nodeRenderer) renderTaskCheckBox(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	mark := r.opts.TaskUnchecked
+	if node.(*east.TaskCheckBox).IsChecked {
+		mark = r.opts.TaskChecked
+	}
+	_, _ = w.WriteString(mark)
+	if node.NextSibling() != nil {
+		_ = w.WriteByte(' ')
+	}
+	return ast.WalkContinue, nil
+}
@@ -0,0 +1,352 @@
+package md2pw
+
+import "fmt"
+
+// HeadingPolicy decides how headings deeper than PukiWiki's three levels are converted.
+type HeadingPolicy string
+
+const (
+	// HeadingLiteral leaves deeper headings as Markdown (e.g. "#### H4").
+	HeadingLiteral HeadingPolicy = "literal"
+	// HeadingClamp converts deeper headings to the deepest PukiWiki heading (***).
+	HeadingClamp HeadingPolicy = "clamp"
+	// HeadingBold converts deeper headings to bold paragraphs (''text'').
+	HeadingBold HeadingPolicy = "bold"
+	// HeadingShift shifts all levels so that the shallowest heading becomes *.
+	// Headings that are still too deep are clamped.
+	HeadingShift HeadingPolicy = "shift"
+	// HeadingError makes the conversion fail on deeper headings.
+	HeadingError HeadingPolicy = "error"
+)
+
+// ListPolicy decides how list items deeper than the list depth are converted.
+type ListPolicy string
+
+const (
+	// ListClamp converts deeper items to items of the deepest level and reports
+	// every clamped line as a warning.
+	ListClamp ListPolicy = "clamp"
+	// ListIndent converts deeper items to items of the deepest level whose text
+	// is indented by non-breaking spaces and a "- " mark, one step per level.
+	ListIndent ListPolicy = "indent"
+	// ListDefinition converts deeper items to definition list descriptions
+	// nested in the deepest item (::|text under -, :::|text under --), clamped
+	// to :::. PukiWiki nests definition lists only in items of a lower level,
+	// so with a list depth of 3 the descriptions (:|text, ::|text, ...) end the
+	// list. Clamped and unnested items are reported as warnings.
+	ListDefinition ListPolicy = "definition"
+	// ListError makes the conversion fail on deeper items.
+	ListError ListPolicy = "error"
+)
+
+// CodeBlockStyle decides how code blocks are converted.
+type CodeBlockStyle string
+
+const (
+	// CodeBlockIndent emits each code line as preformatted text indented by two spaces.
+	CodeBlockIndent CodeBlockStyle = "indent"
+	// CodeBlockPre emits code blocks with the multiline pre plugin (#pre{{ ... }}).
+	CodeBlockPre CodeBlockStyle = "pre"
+	// CodeBlockPlugin emits code blocks with the code plugin (#code(lang){{ ... }})
+	// so that the language is kept for syntax highlighting.
+	CodeBlockPlugin CodeBlockStyle = "code-plugin"
+)
+
+// defaultCodeLanguages maps common fence info strings to code plugin languages.
+var defaultCodeLanguages = map[string]string{
+	"golang": "go",
+	"js":     "javascript",
+	"ts":     "typescript",
+	"py":     "python",
+	"rb":     "ruby",
+	"sh":     "bash",
+	"shell":  "bash",
+	"zsh":    "bash",
+	"yml":    "yaml",
+	"c++":    "cpp",
+}
+
+// LinkStyle decides how links are converted.
+type LinkStyle string
+
+const (
+	// LinkAlias emits links as [[text>url]].
+	LinkAlias LinkStyle = "alias"
+	// LinkURL emits only the destination URL and drops the link text. Links to
+	// Markdown pages and headings are written as [[page]].
+	LinkURL LinkStyle = "url"
+)
+
+// InlineCodeStyle decides how inline code spans are converted.
+type InlineCodeStyle string
+
+const (
+	// InlineCodeBold emits code spans as bold text (''code'').
+	InlineCodeBold InlineCodeStyle = "bold"
+	// InlineCodePlugin emits code spans with the inline code plugin (&code{code};).
+	InlineCodePlugin InlineCodeStyle = "plugin"
+)
+
+// TableAlignStyle decides how the column alignments of tables are converted.
+type TableAlignStyle string
+
+const (
+	// TableAlignCell prefixes every cell of aligned columns with its alignment, e.g. |CENTER: text |.
+	TableAlignCell TableAlignStyle = "cell"
+	// TableAlignFormat writes the alignments once in a format row above the table, e.g. |LEFT:|CENTER:|c.
+	TableAlignFormat TableAlignStyle = "format"
+	// TableAlignNone drops the alignments.
+	TableAlignNone TableAlignStyle = "none"
+)
+
+// LineBreakStyle decides how hard line breaks are converted.
+type LineBreakStyle string
+
+const (
+	// LineBreakTilde ends the line with ~.
+	LineBreakTilde LineBreakStyle = "tilde"
+	// LineBreakBR ends the line with the br plugin (&br;).
+	LineBreakBR LineBreakStyle = "br"
+)
+
+// AnchorStyle decides which fixed anchors ([#id]) are appended to headings.
+type AnchorStyle string
+
+const (
+	// AnchorNone appends no anchors.
+	AnchorNone AnchorStyle = "none"
+	// AnchorHash appends an anchor hashed from the heading's slug, e.g. [#a1b2c3d].
+	AnchorHash AnchorStyle = "hash"
+	// AnchorSlug appends the GitHub-style slug of the heading, e.g. [#getting-started].
+	// Slugs PukiWiki cannot use as anchors, such as non-ASCII ones, are hashed.
+	AnchorSlug AnchorStyle = "slug"
+)
+
+// MaxListDepth is the deepest list level PukiWiki supports.
+const MaxListDepth = 3
+
+// Options configures the conversion.
+type Options struct {
+	HeadingPolicy     HeadingPolicy
+	HeadingAnchors    AnchorStyle
+	ListDepth         int // deepest list level to emit, between 1 and MaxListDepth
+	ListPolicy        ListPolicy
+	CodeBlockStyle    CodeBlockStyle
+	CodeLanguages     map[string]string // fence language to code plugin language
+	LinkStyle         LinkStyle
+	InlineCodeStyle   InlineCodeStyle
+	LineBreakStyle    LineBreakStyle
+	JoinParagraphs    bool // joins the soft-wrapped lines of paragraphs into single lines
+	TableAlign        TableAlignStyle
+	TableWidths       []int    // column widths in pixels written to a format row, 0 leaves a column as is
+	TableHeaderColumn bool     // makes the first column of every row a header column
+	TaskUnchecked     string   // written for unchecked task list items ([ ])
+	TaskChecked       string   // written for checked task list items ([x])
+	ImageParams       []string // extra ref plugin parameters such as "center" or "50%"
+	ImagePage         string   // page local images are attached to; paths are kept when empty
+	AutoContents      int      // insert #contents after the first heading when there are more headings than this, 0 disables it
+
+	// Relative links to .md files are converted to links to PukiWiki pages.
+	DocumentPath string                   // slash separated path of the converted document, relative links are resolved from it
+	PagePrefix   string                   // prefix of the page names, e.g. "Docs" for Docs/setup
+	PageName     func(path string) string // maps a resolved .md path without extension to a page name, overrides PagePrefix
+
+	WarningHandler func(Warning) // receives the constructs PukiWiki cannot represent as they are
+}
+
+// Option modifies Options.
+type Option func(*Options)
+
+// DefaultOptions returns the options used when Convert is called without any Option.
+func DefaultOptions() Options {
+	languages := make(map[string]string, len(defaultCodeLanguages))
+	for from, to := range defaultCodeLanguages {
+		languages[from] = to
+	}
+	return Options{
+		HeadingPolicy:   HeadingLiteral,
+		HeadingAnchors:  AnchorNone,
+		ListDepth:       MaxListDepth,
+		ListPolicy:      ListClamp,
+		CodeBlockStyle:  CodeBlockIndent,
+		CodeLanguages:   languages,
+		LinkStyle:       LinkAlias,
+		InlineCodeStyle: InlineCodeBold,
+		LineBreakStyle:  LineBreakTilde,
+		TableAlign:      TableAlignCell,
+		TaskUnchecked:   "☐",
+		TaskChecked:     "☑",
+	}
+}
+
+func WithHeadingPolicy(p HeadingPolicy) Option {
+	return func(o *Options) { o.HeadingPolicy = p }
+}
+
+// WithHeadingAnchors appends fixed anchors to headings. Links to #slug
+// fragments are converted to the same anchors.
+func WithHeadingAnchors(s AnchorStyle) Option {
+	return func(o *Options) { o.HeadingAnchors = s }
+}
+
+func WithListDepth(depth int) Option {
+	return func(o *Options) { o.ListDepth = depth }
+}
+
+func WithListPolicy(p ListPolicy) Option {
+	return func(o *Options) { o.ListPolicy = p }
+}
+
+func WithCodeBlockStyle(s CodeBlockStyle) Option {
+	return func(o *Options) { o.CodeBlockStyle = s }
+}
+
+// WithCodeLanguages adds mappings from fence languages to code plugin languages.
+func WithCodeLanguages(languages map[string]string) Option {
+	return func(o *Options) {
+		if o.CodeLanguages == nil {
+			o.CodeLanguages = make(map[string]string, len(languages))
+		}
+		for from, to := range languages {
+			o.CodeLanguages[from] = to
+		}
+	}
+}
+
+func WithLinkStyle(s LinkStyle) Option {
+	return func(o *Options) { o.LinkStyle = s }
+}
+
+func WithInlineCodeStyle(s InlineCodeStyle) Option {
+	return func(o *Options) { o.InlineCodeStyle = s }
+}
+
+func WithLineBreakStyle(s LineBreakStyle) Option {
+	return func(o *Options) { o.LineBreakStyle = s }
+}
+
+// WithJoinParagraphs joins the soft-wrapped lines of paragraphs. Lines are
+// joined with a space, or without one between CJK characters.
+func WithJoinParagraphs(enabled bool) Option {
+	return func(o *Options) { o.JoinParagraphs = enabled }
+}
+
+func WithTableAlign(s TableAlignStyle) Option {
+	return func(o *Options) { o.TableAlign = s }
+}
+
+// WithTableWidths sets the widths of the table columns in pixels. They are
+// written to a format row above every table.
+func WithTableWidths(widths ...int) Option {
+	return func(o *Options) { o.TableWidths = widths }
+}
+
+// WithTableHeaderColumn makes the first column of tables a header column (|~ cell |).
+func WithTableHeaderColumn(enabled bool) Option {
+	return func(o *Options) { o.TableHeaderColumn = enabled }
+}
+
+// WithTaskMarks sets what is written for the checkboxes of task list items,
+// e.g. "[ ]" and "[x]" or the calls of a checkbox plugin.
+func WithTaskMarks(unchecked, checked string) Option {
+	return func(o *Options) {
+		o.TaskUnchecked = unchecked
+		o.TaskChecked = checked
+	}
+}
+
+// WithImageParams sets parameters passed to the ref plugin, e.g. "center", "wrap" or "100x50".
+func WithImageParams(params ...string) Option {
+	return func(o *Options) { o.ImageParams = params }
+}
+
+// WithImagePage rewrites local image paths to attachments of the given page.
+func WithImagePage(page string) Option {
+	return func(o *Options) { o.ImagePage = page }
+}
+
+// WithAutoContents inserts #contents after the first heading of documents
+// that have more than n headings and no table of contents marker.
+func WithAutoContents(n int) Option {
+	return func(o *Options) { o.AutoContents = n }
+}
+
+// WithDocumentPath sets the path of the converted document that relative links are resolved from.
+func WithDocumentPath(path string) Option {
+	return func(o *Options) { o.DocumentPath = path }
+}
+
+// WithPagePrefix sets the prefix of the page names that .md links are converted to.
+func WithPagePrefix(prefix string) Option {
+	return func(o *Options) { o.PagePrefix = prefix }
+}
+
+// WithPageName sets how .md paths are mapped to page names.
+func WithPageName(f func(path string) string) Option {
+	return func(o *Options) { o.PageName = f }
+}
+
+// WithWarningHandler sets the function the warnings of the conversion are reported to.
+func WithWarningHandler(f func(Warning)) Option {
+	return func(o *Options) { o.WarningHandler = f }
+}
+
+// WithOptions replaces all options at once.
+func WithOptions(opts Options) Option {
+	return func(o *Options) { *o = opts }
+}
+
+func (o Options) validate() error {
+	switch o.HeadingPolicy {
+	case HeadingLiteral, HeadingClamp, HeadingBold, HeadingShift, HeadingError:
+	default:
+		return fmt.Errorf("unknown heading policy: %q", o.HeadingPolicy)
+	}
+	switch o.HeadingAnchors {
+	case AnchorNone, AnchorHash, AnchorSlug:
+	default:
+		return fmt.Errorf("unknown heading anchor style: %q", o.HeadingAnchors)
+	}
+	if o.ListDepth < 1 || o.ListDepth > MaxListDepth {
+		return fmt.Errorf("list depth must be between 1 and %d: %d", MaxListDepth, o.ListDepth)
+	}
+	switch o.LineBreakStyle {
+	case LineBreakTilde, LineBreakBR:
+	default:
+		return fmt.Errorf("unknown line break style: %q", o.LineBreakStyle)
+	}
+	switch o.TableAlign {
+	case TableAlignCell, TableAlignFormat, TableAlignNone:
+	default:
+		return fmt.Errorf("unknown table align style: %q", o.TableAlign)
+	}
+	for _, width := range o.TableWidths {
+		if width < 0 {
+			return fmt.Errorf("table width must not be negative: %d", width)
+		}
+	}
+	if o.AutoContents < 0 {
+		return fmt.Errorf("auto contents threshold must not be negative: %d", o.AutoContents)
+	}
+	switch o.ListPolicy {
+	case ListClamp, ListIndent, ListDefinition, ListError:
+	default:
+		return fmt.Errorf("unknown list policy: %q", o.ListPolicy)
+	}
+	switch o.CodeBlockStyle {
+	case CodeBlockIndent, CodeBlockPre, CodeBlockPlugin:
+	default:
+		return fmt.Errorf("unknown code block style: %q", o.CodeBlockStyle)
+	}
+	switch o.LinkStyle {
+	case LinkAlias, LinkURL:
+	default:
+		return fmt.Errorf("unknown link style: %q", o.LinkStyle)
+	}
+	switch o.InlineCodeStyle {
+	case InlineCodeBold, InlineCodePlugin:
+	default:
+		return fmt.Errorf("unknown inline code style: %q", o.InlineCodeStyle)
+	}
+	return nil
+}
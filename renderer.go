@@ -0,0 +1,282 @@
+package md2pw
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// brTagRe matches a line break tag, e.g. <br> or <br />.
+var brTagRe = regexp.MustCompile(`(?i)^<br\s*/?>$`)
+
+// nodeRenderer renders a goldmark AST as PukiWiki notation.
+// Node kinds without a PukiWiki counterpart are written back as Markdown.
+type nodeRenderer struct {
+	opts Options
+
+	headingShift     int                       // levels subtracted from every heading
+	headingSlugs     map[ast.Node]string       // GitHub-style slugs of the headings
+	contentsNode     ast.Node                  // heading #contents is inserted after
+	footnotes        map[int]*east.Footnote    // footnote definitions by their index
+	footnoteText     map[*east.Footnote]string // rendered footnotes
+	footnoteVisiting map[*east.Footnote]bool   // footnotes being rendered, to break reference cycles
+	inline           renderer.Renderer         // renders footnote content apart from the document
+
+	mergeCells  map[ast.Node]byte // merge cells of the current table and their mark, > or ~
+	headerCells map[ast.Node]bool // header cells of the current table
+}
+
+func newRenderer(opts Options) renderer.Renderer {
+	// Lower values take precedence, so these funcs win over the HTML
+	// renderers that goldmark extensions register.
+	return renderer.NewRenderer(
+		renderer.WithNodeRenderers(util.Prioritized(&nodeRenderer{opts: opts}, 100)),
+	)
+}
+
+func (r *nodeRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	// blocks
+	reg.Register(ast.KindDocument, r.renderDocument)
+	reg.Register(ast.KindHeading, r.renderHeading)
+	reg.Register(ast.KindParagraph, r.renderParagraph)
+	reg.Register(ast.KindTextBlock, r.renderTextBlock)
+	reg.Register(ast.KindBlockquote, r.renderBlockquote)
+	reg.Register(ast.KindList, r.renderList)
+	reg.Register(ast.KindListItem, r.renderListItem)
+	reg.Register(ast.KindCodeBlock, r.renderCodeBlock)
+	reg.Register(ast.KindFencedCodeBlock, r.renderCodeBlock)
+	reg.Register(ast.KindHTMLBlock, r.renderHTMLBlock)
+	reg.Register(ast.KindThematicBreak, r.renderThematicBreak)
+	r.registerTableFuncs(reg)
+	r.registerDefinitionFuncs(reg)
+	r.registerFootnoteFuncs(reg)
+
+	// inlines
+	reg.Register(ast.KindText, r.renderText)
+	reg.Register(ast.KindString, r.renderString)
+	reg.Register(ast.KindEmphasis, r.renderEmphasis)
+	reg.Register(east.KindStrikethrough, r.renderStrikethrough)
+	reg.Register(east.KindTaskCheckBox, r.renderTaskCheckBox)
+	reg.Register(ast.KindCodeSpan, r.renderCodeSpan)
+	reg.Register(ast.KindLink, r.renderLink)
+	reg.Register(ast.KindImage, r.renderImage)
+	reg.Register(ast.KindAutoLink, r.renderAutoLink)
+	reg.Register(ast.KindRawHTML, r.renderRawHTML)
+}
+
+// beginBlock writes what has to precede a block: a blank line between
+// top-level blocks and the quote marker for paragraphs inside a blockquote.
+func beginBlock(w util.BufWriter, node ast.Node) {
+	switch p := node.Parent().(type) {
+	case *ast.Document:
+		for prev := node.PreviousSibling(); prev != nil; prev = prev.PreviousSibling() {
+			if !isEmptyBlock(prev) {
+				_ = w.WriteByte('\n')
+				break
+			}
+		}
+	case *ast.Blockquote:
+		if _, ok := node.(*ast.Paragraph); ok {
+			_, _ = w.WriteString(strings.Repeat(">", quoteLevel(p)) + " ")
+			// Quote lines of the same level are joined, ~ starts another
+			// paragraph. A nested quote is already ended by its closing line.
+			prev := node.PreviousSibling()
+			for prev != nil && isEmptyBlock(prev) {
+				prev = prev.PreviousSibling()
+			}
+			if _, ok := prev.(*ast.Blockquote); prev != nil && !ok {
+				_ = w.WriteByte('~')
+			}
+		}
+	}
+}
+
+// isEmptyBlock reports whether the node is a text block left without text,
+// e.g. one that only held link reference definitions.
+func isEmptyBlock(node ast.Node) bool {
+	switch node.(type) {
+	case *ast.Paragraph, *ast.TextBlock:
+		return node.ChildCount() == 0
+	}
+	return false
+}
+
+func (r *nodeRenderer) renderDocument(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	if r.opts.HeadingPolicy == HeadingShift {
+		r.headingShift = minHeadingLevel(node) - 1
+	}
+	if r.opts.HeadingAnchors != AnchorNone {
+		r.headingSlugs = headingSlugs(node, source)
+		// Only PukiWiki headings carry an anchor that links can point at.
+		for h := range r.headingSlugs {
+			if !r.isPageHeading(h.(*ast.Heading)) {
+				delete(r.headingSlugs, h)
+			}
+		}
+	}
+	r.footnotes = footnotes(node)
+	r.footnoteText = make(map[*east.Footnote]string)
+	r.footnoteVisiting = make(map[*east.Footnote]bool)
+	if !removeGeneratedTOC(node, source) && r.opts.AutoContents > 0 {
+		r.contentsNode = contentsHeading(node, r.opts.AutoContents)
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *nodeRenderer) renderParagraph(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if isEmptyBlock(node) {
+		return ast.WalkContinue, nil
+	}
+	if isTOCMarker(node, source) {
+		if entering {
+			beginBlock(w, node)
+			writeContents(w)
+		}
+		return ast.WalkSkipChildren, nil
+	}
+	if entering {
+		if isItemBody(node.Parent()) {
+			r.beginItemBlock(w, source, node)
+		} else {
+			beginBlock(w, node)
+		}
+	} else {
+		_ = w.WriteByte('\n')
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *nodeRenderer) renderTextBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if isEmptyBlock(node) {
+		return ast.WalkContinue, nil
+	}
+	if !entering {
+		_ = w.WriteByte('\n')
+	} else if isItemBody(node.Parent()) {
+		r.beginItemBlock(w, source, node)
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *nodeRenderer) renderHTMLBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*ast.HTMLBlock)
+	beginBlock(w, n)
+	if isTOCMarker(n, source) {
+		writeContents(w)
+		return ast.WalkContinue, nil
+	}
+	writeLines(w, source, n.Lines(), "")
+	if n.HasClosure() {
+		_, _ = w.Write(n.ClosureLine.Value(source))
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *nodeRenderer) renderThematicBreak(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if _, ok := node.Parent().(*ast.ListItem); ok {
+		if entering {
+			r.warn(source, node, "horizontal rule inside a list item is dropped")
+		}
+		return ast.WalkContinue, nil
+	}
+	if entering {
+		beginBlock(w, node)
+		_, _ = w.WriteString("----\n")
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *nodeRenderer) renderText(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*ast.Text)
+	_, _ = w.WriteString(r.escapeText(n, source))
+	if n.HardLineBreak() {
+		// Text kept on one line cannot end its lines with ~.
+		if r.opts.LineBreakStyle == LineBreakBR || isOneLine(n) {
+			_, _ = w.WriteString("&br;")
+		} else {
+			_ = w.WriteByte('~')
+		}
+	}
+	switch {
+	case n.HardLineBreak() && isOneLine(n):
+		_, _ = w.WriteString(softBreak(n, source))
+	case n.HardLineBreak():
+		_ = w.WriteByte('\n')
+	case n.SoftLineBreak():
+		if r.opts.JoinParagraphs || isOneLine(n) {
+			_, _ = w.WriteString(softBreak(n, source))
+		} else {
+			_ = w.WriteByte('\n')
+		}
+	}
+	return ast.WalkContinue, nil
+}
+
+// isOneLine reports whether the text has to be written on one line: footnotes
+// are inlined, and PukiWiki headings and inline markup end with the line.
+func isOneLine(n ast.Node) bool {
+	return hasAncestor[*east.Footnote](n) || hasAncestor[*ast.Heading](n) || hasAncestor[*ast.Link](n) ||
+		hasAncestor[*ast.Emphasis](n) || hasAncestor[*east.Strikethrough](n)
+}
+
+func (r *nodeRenderer) renderString(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		_, _ = w.Write(node.(*ast.String).Value)
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *nodeRenderer) renderRawHTML(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*ast.RawHTML)
+	if brTagRe.Match(n.Segments.Value(source)) {
+		// PukiWiki shows HTML as text, &br; breaks the line instead.
+		_, _ = w.WriteString("&br;")
+		return ast.WalkSkipChildren, nil
+	}
+	for i := 0; i < n.Segments.Len(); i++ {
+		seg := n.Segments.At(i)
+		_, _ = w.Write(seg.Value(source))
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+// lineOf returns the 1-based line number of the offset in the source.
+func lineOf(source []byte, offset int) int {
+	return bytes.Count(source[:offset], []byte("\n")) + 1
+}
+
+// plainText returns the text of the node's children without any markup.
+func plainText(source []byte, node ast.Node) string {
+	var buf bytes.Buffer
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		switch c := child.(type) {
+		case *ast.Text:
+			buf.Write(c.Segment.Value(source))
+			if c.SoftLineBreak() || c.HardLineBreak() {
+				buf.WriteByte(' ')
+			}
+		case *ast.String:
+			buf.Write(c.Value)
+		default:
+			buf.WriteString(plainText(source, child))
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,438 @@
+package md2pw
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	reverseHeadingRe = regexp.MustCompile(`^(\*{1,3})\s*(.*?)\s*(?:\[#[^\]]*\])?$`)
+	reverseListRe    = regexp.MustCompile(`^([-+]{1,3})\s*(.*)$`)
+	reverseRuleRe    = regexp.MustCompile(`^-{4,}\s*$`)
+	reverseDefRe     = regexp.MustCompile(`^(:{1,3})([^|]*)\|(.*)$`)
+	reverseRefRe     = regexp.MustCompile(`^#ref\((.*)\)\s*$`)
+	reverseAlignRe   = regexp.MustCompile(`^(LEFT|CENTER|RIGHT):`)
+	reversePluginRe  = regexp.MustCompile(`^#(?:pre|code)(?:\(([^)]*)\))?(\{\{+)\s*$`)
+	reverseQuoteRe   = regexp.MustCompile(`^(>{1,3})\s*(.*)$`)
+	reverseUnquoteRe = regexp.MustCompile(`^(<{1,3})\s*(.*)$`)
+	reverseMarkerRe  = regexp.MustCompile(`^(?:[-+*](?:\s|$)|\d{1,9}[.)](?:\s|$)|[#>]|=+\s*$)`)
+
+	reverseInlineRefRe = regexp.MustCompile(`&ref\(([^)]*)\);`)
+	reverseCodeRe      = regexp.MustCompile(`&code\{(.*?)\};`)
+	reverseAliasLinkRe = regexp.MustCompile(`\[\[([^\]]+?)>([^\]]+?)\]\]`)
+	reversePageLinkRe  = regexp.MustCompile(`\[\[([^\]]+?)\]\]`)
+	reverseItalicRe    = regexp.MustCompile(`'''(.+?)'''`)
+	reverseBoldRe      = regexp.MustCompile(`''(.+?)''`)
+	reverseStrikeRe    = regexp.MustCompile(`%%(.+?)%%`)
+	reverseVerbatimRe  = regexp.MustCompile("\x00(\\d+)\x00")
+)
+
+// ToMarkdown converts PukiWiki notation to Markdown (GFM).
+// It is the reverse of Convert and understands headings, lists, tables,
+// quotes, preformatted text, images and the inline notations Convert emits.
+// Plain text is escaped where Markdown would read it as markup.
+func ToMarkdown(pukiwiki []byte) (string, error) {
+	src := strings.ReplaceAll(string(pukiwiki), "\r\n", "\n")
+	lines := strings.Split(strings.TrimRight(src, "\n"), "\n")
+
+	output := reverseBlocks(lines)
+	if output != "" && strings.HasSuffix(src, "\n") {
+		output += "\n"
+	}
+	return output, nil
+}
+
+// reverseBlocks converts the lines block by block.
+func reverseBlocks(lines []string) string {
+	var blocks []string
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		switch {
+		case strings.TrimSpace(line) == "", strings.HasPrefix(line, "//"):
+			i++
+		case reverseQuoteRe.MatchString(line):
+			var quote string
+			quote, i = reverseQuote(lines, i)
+			blocks = append(blocks, quote)
+		case reverseUnquoteRe.MatchString(line):
+			// Closing a quote that is not open only leaves the text after it.
+			lines[i] = reverseUnquoteRe.FindStringSubmatch(line)[2]
+		case reverseRuleRe.MatchString(line):
+			blocks = append(blocks, "---")
+			i++
+		case strings.HasPrefix(line, "*"):
+			blocks = append(blocks, reverseHeading(line))
+			i++
+		case reverseListRe.MatchString(line):
+			j := i + 1
+			for j < len(lines) && (reverseListRe.MatchString(lines[j]) && !reverseRuleRe.MatchString(lines[j]) || isReversePlainLine(lines[j])) {
+				j++
+			}
+			blocks = append(blocks, reverseList(lines[i:j]))
+			i = j
+		case reverseDefRe.MatchString(line):
+			j := i + 1
+			for j < len(lines) && reverseDefRe.MatchString(lines[j]) {
+				j++
+			}
+			blocks = append(blocks, reverseDefinitionList(lines[i:j]))
+			i = j
+		case strings.HasPrefix(line, "|"):
+			j := i + 1
+			for j < len(lines) && strings.HasPrefix(lines[j], "|") {
+				j++
+			}
+			blocks = append(blocks, reverseTable(lines[i:j]))
+			i = j
+		case strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t"):
+			j := i + 1
+			for j < len(lines) && (strings.HasPrefix(lines[j], " ") || strings.HasPrefix(lines[j], "\t")) {
+				j++
+			}
+			blocks = append(blocks, reversePre(lines[i:j]))
+			i = j
+		case reversePluginRe.MatchString(line):
+			m := reversePluginRe.FindStringSubmatch(line)
+			closing := strings.Repeat("}", len(m[2]))
+			j := i + 1
+			// Like PukiWiki, the block ends at any line containing the braces.
+			for j < len(lines) && !strings.Contains(lines[j], closing) {
+				j++
+			}
+			out := append([]string{"```" + m[1]}, lines[i+1:min(j, len(lines))]...)
+			blocks = append(blocks, strings.Join(append(out, "```"), "\n"))
+			i = j + 1
+		case strings.TrimSpace(line) == "#contents":
+			blocks = append(blocks, "[[_TOC_]]")
+			i++
+		case reverseRefRe.MatchString(line):
+			blocks = append(blocks, reverseRef(reverseRefRe.FindStringSubmatch(line)[1]))
+			i++
+		default:
+			j := i + 1
+			for j < len(lines) && isReversePlainLine(lines[j]) {
+				j++
+			}
+			paragraph := make([]string, 0, j-i)
+			for _, l := range lines[i:j] {
+				// A leading ~ only marks the line as a paragraph, a trailing one breaks the line.
+				l = strings.TrimPrefix(l, "~")
+				lineBreak := ""
+				if rest, ok := strings.CutSuffix(l, "~"); ok {
+					l, lineBreak = rest, `\`
+				}
+				paragraph = append(paragraph, escapeMarkdownLineStart(reverseInline(l))+lineBreak)
+			}
+			blocks = append(blocks, strings.Join(paragraph, "\n"))
+			i = j
+		}
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// reverseQuote converts the blockquote starting at lines[i] and returns the
+// index of the line after it. The quote ends at a blank line or where < closes
+// its last level, the lines in between belong to the level last opened.
+func reverseQuote(lines []string, i int) (string, int) {
+	type group struct {
+		level int
+		lines []string
+	}
+	var groups []group
+	level := 0
+	for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+		line := lines[i]
+		if m := reverseQuoteRe.FindStringSubmatch(line); m != nil {
+			level, line = len(m[1]), m[2]
+		} else if m := reverseUnquoteRe.FindStringSubmatch(line); m != nil {
+			// < closes the quotes from its own level, << goes back to level 1.
+			level = min(level, len(m[1])-1)
+			if level == 0 {
+				// Text after the closing marks continues outside the quote.
+				lines[i] = m[2]
+				if m[2] == "" {
+					i++
+				}
+				break
+			}
+			line = m[2]
+		}
+		if len(groups) == 0 || groups[len(groups)-1].level != level {
+			groups = append(groups, group{level: level})
+		}
+		g := &groups[len(groups)-1]
+		g.lines = append(g.lines, line)
+	}
+
+	var out []string
+	for k, g := range groups {
+		if k > 0 {
+			// A blank quote line separates the levels, at the level they share.
+			out = append(out, strings.Repeat(">", min(g.level, groups[k-1].level)))
+		}
+		prefix := strings.Repeat("> ", g.level)
+		for _, l := range strings.Split(reverseBlocks(g.lines), "\n") {
+			out = append(out, strings.TrimRight(prefix+l, " "))
+		}
+	}
+	return strings.Join(out, "\n"), i
+}
+
+// escapeMarkdownLineStart escapes what Markdown would read as the start of
+// another block at the beginning of a line of text.
+func escapeMarkdownLineStart(s string) string {
+	if !reverseMarkerRe.MatchString(s) {
+		return s
+	}
+	if i := strings.IndexAny(s, ".)"); i > 0 && s[0] >= '0' && s[0] <= '9' {
+		return s[:i] + `\` + s[i:]
+	}
+	return `\` + s
+}
+
+// isReversePlainLine reports whether the line continues the preceding text.
+func isReversePlainLine(line string) bool {
+	if strings.TrimSpace(line) == "" {
+		return false
+	}
+	switch line[0] {
+	case '*', '-', '+', '|', ' ', '\t', '#', '/', '~', '>', '<':
+		return false
+	}
+	return true
+}
+
+func reverseHeading(line string) string {
+	m := reverseHeadingRe.FindStringSubmatch(line)
+	return strings.Repeat("#", len(m[1])) + " " + reverseInline(m[2])
+}
+
+func reverseList(lines []string) string {
+	var out []string
+	// widths of the Markdown markers of the enclosing items, used for indentation
+	var widths []int
+	for _, line := range lines {
+		m := reverseListRe.FindStringSubmatch(line)
+		if m == nil {
+			// Text following an item continues that item.
+			indent := 0
+			for _, w := range widths {
+				indent += w
+			}
+			out = append(out, strings.Repeat(" ", indent)+escapeMarkdownLineStart(reverseInline(line)))
+			continue
+		}
+
+		level := len(m[1])
+		if level > len(widths)+1 {
+			level = len(widths) + 1
+		}
+		widths = widths[:level-1]
+		indent := 0
+		for _, w := range widths {
+			indent += w
+		}
+
+		marker := "- "
+		if m[1][len(m[1])-1] == '+' {
+			marker = "1. "
+		}
+		widths = append(widths, len(marker))
+		out = append(out, strings.Repeat(" ", indent)+marker+escapeMarkdownLineStart(reverseInline(reverseTask(m[2]))))
+	}
+	return strings.Join(out, "\n")
+}
+
+// reverseDefinitionList converts :term|description lines to the definition
+// lists of PHP Markdown Extra, nesting deeper levels in the descriptions.
+func reverseDefinitionList(lines []string) string {
+	var out []string
+	// whether the last line is a description, which would take a term right after it as its text
+	afterDesc := false
+	for _, line := range lines {
+		m := reverseDefRe.FindStringSubmatch(line)
+		indent := strings.Repeat(" ", 4*(len(m[1])-1))
+		if term := strings.TrimSpace(m[2]); term != "" {
+			if afterDesc {
+				out = append(out, "")
+			}
+			out = append(out, indent+escapeMarkdownLineStart(reverseInline(term)))
+			afterDesc = false
+		}
+		if desc := strings.TrimSpace(m[3]); desc != "" {
+			out = append(out, indent+":   "+escapeMarkdownLineStart(reverseInline(desc)))
+			afterDesc = true
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// reverseTask converts the default task list marks back to GFM checkboxes.
+func reverseTask(item string) string {
+	if rest, ok := strings.CutPrefix(item, "☐"); ok {
+		return strings.TrimSpace("[ ] " + strings.TrimSpace(rest))
+	}
+	if rest, ok := strings.CutPrefix(item, "☑"); ok {
+		return strings.TrimSpace("[x] " + strings.TrimSpace(rest))
+	}
+	return item
+}
+
+func reverseTable(lines []string) string {
+	var rows [][]string
+	// alignments of the columns, from the format row or the cells of the first row
+	var aligns []string
+	for _, line := range lines {
+		line = strings.TrimRight(line, " ")
+		format := false
+		switch {
+		case strings.HasSuffix(line, "|c"):
+			format = true
+			line = line[:len(line)-1]
+		case strings.HasSuffix(line, "|h"), strings.HasSuffix(line, "|f"):
+			line = line[:len(line)-1]
+		}
+
+		cells := strings.Split(strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|"), "|")
+		// number of > cells waiting for the cell they are merged with
+		colspan := 0
+		row := make([]string, 0, len(cells))
+		for i, cell := range cells {
+			align := ""
+			if m := reverseAlignRe.FindStringSubmatch(cell); m != nil {
+				align = m[1]
+				cell = cell[len(m[0]):]
+			}
+			if align != "" && (format || len(rows) == 0) {
+				for len(aligns) <= i {
+					aligns = append(aligns, "")
+				}
+				aligns[i] = align
+			}
+			switch cell {
+			case ">":
+				// Merged with the cell on the right, which is merged with < cells in Markdown.
+				colspan++
+				continue
+			case "~":
+				row = append(row, "^")
+				continue
+			}
+			cell = strings.TrimPrefix(strings.TrimSpace(cell), "~")
+			row = append(row, strings.ReplaceAll(reverseInline(strings.TrimSpace(cell)), charRef('|'), `\|`))
+			for ; colspan > 0; colspan-- {
+				row = append(row, "<")
+			}
+		}
+		if !format {
+			rows = append(rows, row)
+		}
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+
+	// GFM needs exactly one header row, so the first row always becomes it.
+	out := []string{"| " + strings.Join(rows[0], " | ") + " |"}
+	separator := make([]string, len(rows[0]))
+	for i := range separator {
+		separator[i] = "---"
+		if i < len(aligns) {
+			switch aligns[i] {
+			case "LEFT":
+				separator[i] = ":--"
+			case "CENTER":
+				separator[i] = ":-:"
+			case "RIGHT":
+				separator[i] = "--:"
+			}
+		}
+	}
+	out = append(out, "| "+strings.Join(separator, " | ")+" |")
+	for _, row := range rows[1:] {
+		out = append(out, "| "+strings.Join(row, " | ")+" |")
+	}
+	return strings.Join(out, "\n")
+}
+
+func reversePre(lines []string) string {
+	// Convert indents code by two spaces, strip them when every line has them.
+	indent := "  "
+	for _, line := range lines {
+		if !strings.HasPrefix(line, indent) {
+			indent = line[:1]
+			break
+		}
+	}
+	out := []string{"```"}
+	for _, line := range lines {
+		out = append(out, strings.TrimPrefix(line, indent))
+	}
+	out = append(out, "```")
+	return strings.Join(out, "\n")
+}
+
+func reverseRef(args string) string {
+	parts := strings.Split(args, ",")
+	alt := ""
+	if len(parts) > 1 {
+		alt = strings.Trim(parts[len(parts)-1], `"`)
+	}
+	return "![" + alt + "](" + parts[0] + ")"
+}
+
+func reverseInline(s string) string {
+	// Images, code and link destinations are set aside, so that only the text is escaped.
+	var verbatim []string
+	setAside := func(md string) string {
+		verbatim = append(verbatim, md)
+		return "\x00" + strconv.Itoa(len(verbatim)-1) + "\x00"
+	}
+	s = reverseInlineRefRe.ReplaceAllStringFunc(s, func(m string) string {
+		return setAside(reverseRef(reverseInlineRefRe.FindStringSubmatch(m)[1]))
+	})
+	s = reverseCodeRe.ReplaceAllStringFunc(s, func(m string) string {
+		return setAside("`" + reverseCodeRe.FindStringSubmatch(m)[1] + "`")
+	})
+	s = reverseAliasLinkRe.ReplaceAllStringFunc(s, func(m string) string {
+		link := reverseAliasLinkRe.FindStringSubmatch(m)
+		return "[" + link[1] + "](" + setAside(link[2]) + ")"
+	})
+	s = reversePageLinkRe.ReplaceAllStringFunc(s, func(m string) string {
+		page := reversePageLinkRe.FindStringSubmatch(m)[1]
+		return "[" + page + "](" + setAside(page) + ")"
+	})
+	s = escapeMarkdownInline(s)
+
+	s = reverseItalicRe.ReplaceAllString(s, "*$1*")
+	s = reverseBoldRe.ReplaceAllString(s, "**$1**")
+	s = reverseStrikeRe.ReplaceAllString(s, "~~$1~~")
+	s = strings.ReplaceAll(s, "&br;", "<br>")
+	return reverseVerbatimRe.ReplaceAllStringFunc(s, func(m string) string {
+		k, _ := strconv.Atoi(reverseVerbatimRe.FindStringSubmatch(m)[1])
+		return verbatim[k]
+	})
+}
+
+// escapeMarkdownInline escapes the characters Markdown reads as inline markup.
+// An _ between letters or digits is left alone, it never emphasises.
+func escapeMarkdownInline(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\', '*', '`':
+			b.WriteByte('\\')
+		case '_':
+			if i == 0 || i+1 == len(s) || !isWordByte(s[i-1]) || !isWordByte(s[i+1]) {
+				b.WriteByte('\\')
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func isWordByte(c byte) bool {
+	return c >= 0x80 || c >= '0' && c <= '9' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+}
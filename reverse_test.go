@@ -0,0 +1,166 @@
+package md2pw
+
+import (
+	"testing"
+)
+
+func TestToMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		expected string
+	}{
+		{
+			name:     "見出し",
+			input:    []byte("* H1\n** H2\n*** H3"),
+			expected: "# H1\n\n## H2\n\n### H3",
+		},
+		{
+			name:     "アンカー付きの見出し",
+			input:    []byte("* Title [#a1b2c3d4]"),
+			expected: "# Title",
+		},
+		{
+			name:     "セルの書式から列の揃えを戻す",
+			input:    []byte("|LEFT:~ A |CENTER:~ B |~ C |\n|LEFT: x |CENTER: y | z |"),
+			expected: "| A | B | C |\n| :-- | :-: | --- |\n| x | y | z |",
+		},
+		{
+			name:     "書式指定行から列の揃えを戻す",
+			input:    []byte("|RIGHT:100|CENTER:|c\n|~ A |~ B |\n| x | y |"),
+			expected: "| A | B |\n| --: | :-: |\n| x | y |",
+		},
+		{
+			name:     "結合されたセル",
+			input:    []byte("|~ A |~ B |~ C |\n|>|>| x |\n|~| y | z |"),
+			expected: "| A | B | C |\n| --- | --- | --- |\n| x | < | < |\n| ^ | y | z |",
+		},
+		{
+			name:     "セル内のエスケープされた | と &br;",
+			input:    []byte("|~ A |~ B |\n| a &#x7c; b | line1&br;line2 |"),
+			expected: "| A | B |\n| --- | --- |\n| a \\| b | line1<br>line2 |",
+		},
+		{
+			name:     "タスクリスト",
+			input:    []byte("-☐ todo\n-☑ done\n--☐"),
+			expected: "- [ ] todo\n- [x] done\n  - [ ]",
+		},
+		{
+			name:     "定義リスト",
+			input:    []byte(":Apple|fruit\n:|company\n:Outer|desc\n::Inner|inner"),
+			expected: "Apple\n:   fruit\n:   company\n\nOuter\n:   desc\n\n    Inner\n    :   inner",
+		},
+		{
+			name:     "行末の ~ は強制改行",
+			input:    []byte("line1~\nline2"),
+			expected: "line1\\\nline2",
+		},
+		{
+			name:     "#contents",
+			input:    []byte("* Title\n#contents\n** A"),
+			expected: "# Title\n\n[[_TOC_]]\n\n## A",
+		},
+		{
+			name:     "unordered list",
+			input:    []byte("-item1\n--nested\n---deep\n-item2"),
+			expected: "- item1\n  - nested\n    - deep\n- item2",
+		},
+		{
+			name:     "ordered list",
+			input:    []byte("+ item1\n++ nested\n+ item2"),
+			expected: "1. item1\n   1. nested\n1. item2",
+		},
+		{
+			name:     "ordered と unordered の混在",
+			input:    []byte("+item1\n--nested\n-+mixed"),
+			expected: "1. item1\n   - nested\n   1. mixed",
+		},
+		{
+			name:     "リスト項目の続きの行",
+			input:    []byte("-item\ncontinued"),
+			expected: "- item\n  continued",
+		},
+		{
+			name:     "テーブル",
+			input:    []byte("|~ A |~ B |\n| 1 | 2 |"),
+			expected: "| A | B |\n| --- | --- |\n| 1 | 2 |",
+		},
+		{
+			name:     "書式指定行付きのテーブル",
+			input:    []byte("|LEFT:|CENTER:|c\n|A|B|h\n|1|2|"),
+			expected: "| A | B |\n| :-- | :-: |\n| 1 | 2 |",
+		},
+		{
+			name:     "整形済みテキスト",
+			input:    []byte(" line1\n   indented"),
+			expected: "```\nline1\n  indented\n```",
+		},
+		{
+			name:     "pre プラグイン",
+			input:    []byte("#pre{{\nline1\n  indented\n}}"),
+			expected: "```\nline1\n  indented\n```",
+		},
+		{
+			name:     "code プラグイン",
+			input:    []byte("#code(go){{{\nfunc main() {\n}}\n}}}\ntext"),
+			expected: "```go\nfunc main() {\n}}\n```\n\ntext",
+		},
+		{
+			name:     "行の途中の閉じ括弧でプラグインが終わる",
+			input:    []byte("#pre{{\nline\n  }} end\ntext"),
+			expected: "```\nline\n```\n\ntext",
+		},
+		{
+			name:     "インライン要素",
+			input:    []byte("''bold'' '''italic''' %%strike%% [[link>https://example.com]] [[FrontPage]]"),
+			expected: "**bold** *italic* ~~strike~~ [link](https://example.com) [FrontPage](FrontPage)",
+		},
+		{
+			name:     "Link内のBold",
+			input:    []byte("[[''bold''>https://example.com]]"),
+			expected: "[**bold**](https://example.com)",
+		},
+		{
+			name:     "段落の先頭のチルダ",
+			input:    []byte("~-not a list"),
+			expected: "-not a list",
+		},
+		{
+			name:     "Markdownの記法になる行頭はエスケープされる",
+			input:    []byte("~- item\n\n1. step\n\n~# note\n\n~> not quoted"),
+			expected: "\\- item\n\n1\\. step\n\n\\# note\n\n\\> not quoted",
+		},
+		{
+			name:     "Markdownのインライン記法はエスケープされる",
+			input:    []byte("a*star* _x_ snake_case `tick` ''bold'' [[a_b>https://example.com/_x_]]"),
+			expected: "a\\*star\\* \\_x\\_ snake_case \\`tick\\` **bold** [a_b](https://example.com/_x_)",
+		},
+		{
+			name:     "引用",
+			input:    []byte("> quote\n<\nafter"),
+			expected: "> quote\n\nafter",
+		},
+		{
+			name:     "ネストした引用とリストを含む引用",
+			input:    []byte("> outer\n>> inner\n<<\n> ~again\n-item\n<\n\ntext"),
+			expected: "> outer\n>\n> > inner\n>\n> again\n>\n> - item\n\ntext",
+		},
+		{
+			name:     "水平線とコメント",
+			input:    []byte("// comment\ntext\n\n----"),
+			expected: "text\n\n---",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ToMarkdown(tt.input)
+			if err != nil {
+				t.Fatalf("ToMarkdown returned error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package md2pw
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+func (r *nodeRenderer) registerTableFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(east.KindTable, r.renderTable)
+	reg.Register(east.KindTableHeader, r.renderTableRow)
+	reg.Register(east.KindTableRow, r.renderTableRow)
+	reg.Register(east.KindTableCell, r.renderTableCell)
+}
+
+func (r *nodeRenderer) renderTable(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		r.prepareCells(node, source)
+		beginBlock(w, node)
+		if r.opts.TableAlign == TableAlignFormat || len(r.opts.TableWidths) > 0 {
+			r.writeFormatRow(w, node.(*east.Table).Alignments)
+		}
+	}
+	return ast.WalkContinue, nil
+}
+
+// writeFormatRow writes the row (|LEFT:100|CENTER:|c) that sets the alignment
+// and width of the columns of the rows below it.
+func (r *nodeRenderer) writeFormatRow(w util.BufWriter, alignments []east.Alignment) {
+	for i, a := range alignments {
+		_ = w.WriteByte('|')
+		if r.opts.TableAlign == TableAlignFormat {
+			_, _ = w.WriteString(alignPrefix(a))
+		}
+		if i < len(r.opts.TableWidths) && r.opts.TableWidths[i] > 0 {
+			_, _ = w.WriteString(strconv.Itoa(r.opts.TableWidths[i]))
+		}
+	}
+	_, _ = w.WriteString("|c\n")
+}
+
+func (r *nodeRenderer) renderTableRow(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		_, _ = w.WriteString("|")
+	} else {
+		_ = w.WriteByte('\n')
+	}
+	return ast.WalkContinue, nil
+}
+
+// prepareCells finds the merge cells and header cells of the table.
+// A cell holding only < is merged with the cell on its left and one holding
+// only ^ with the cell above. PukiWiki merges > cells with the cell on their
+// right, so the content of a span is moved after its < cells.
+func (r *nodeRenderer) prepareCells(table ast.Node, source []byte) {
+	r.mergeCells = make(map[ast.Node]byte)
+	r.headerCells = make(map[ast.Node]bool)
+	for row := table.FirstChild(); row != nil; row = row.NextSibling() {
+		if _, ok := row.(*east.TableHeader); ok {
+			for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+				r.headerCells[cell] = true
+			}
+		} else if first := row.FirstChild(); first != nil && r.opts.TableHeaderColumn {
+			r.headerCells[first] = true
+		}
+
+		var content ast.Node
+		for cell := row.FirstChild(); cell != nil; {
+			next := cell.NextSibling()
+			switch strings.TrimSpace(plainText(source, cell)) {
+			case "<":
+				if content != nil {
+					r.mergeCells[cell] = '>'
+					row.InsertBefore(row, content, cell)
+				}
+			case "^":
+				if row != table.FirstChild() {
+					r.mergeCells[cell] = '~'
+				}
+				content = cell
+			default:
+				content = cell
+			}
+			cell = next
+		}
+	}
+}
+
+func (r *nodeRenderer) renderTableCell(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if merge, ok := r.mergeCells[node]; ok {
+		if entering {
+			_, _ = w.Write([]byte{merge, '|'})
+		}
+		return ast.WalkSkipChildren, nil
+	}
+	if !entering {
+		_, _ = w.WriteString(" |")
+		return ast.WalkContinue, nil
+	}
+	// The format has to come first, PukiWiki reads it before the header mark.
+	if r.opts.TableAlign == TableAlignCell {
+		_, _ = w.WriteString(alignPrefix(node.(*east.TableCell).Alignment))
+	}
+	if r.headerCells[node] {
+		_, _ = w.WriteString("~ ")
+	} else {
+		_, _ = w.WriteString(" ")
+	}
+	return ast.WalkContinue, nil
+}
+
+// alignPrefix returns the cell format for the alignment, e.g. CENTER:.
+func alignPrefix(a east.Alignment) string {
+	if a == east.AlignNone {
+		return ""
+	}
+	return strings.ToUpper(a.String()) + ":"
+}
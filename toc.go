@@ -0,0 +1,91 @@
+package md2pw
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/util"
+)
+
+var (
+	// tocMarkerRe matches the table of contents markers of GitLab ([[_TOC_]])
+	// and markdown-toc (<!-- toc -->).
+	tocMarkerRe = regexp.MustCompile(`(?i)^(?:\[\[_TOC_\]\]|<!--\s*toc\s*-->)$`)
+	// tocStopRe matches the comment closing the list markdown-toc generates.
+	tocStopRe = regexp.MustCompile(`(?i)^<!--\s*tocstop\s*-->$`)
+)
+
+// isTOCMarker reports whether the top-level block only holds a table of contents marker.
+func isTOCMarker(node ast.Node, source []byte) bool {
+	if _, ok := node.Parent().(*ast.Document); !ok {
+		return false
+	}
+	switch node.(type) {
+	case *ast.Paragraph, *ast.HTMLBlock:
+		return tocMarkerRe.Match(blockText(node, source))
+	}
+	return false
+}
+
+// blockText returns the source lines of the block without surrounding spaces.
+func blockText(node ast.Node, source []byte) []byte {
+	var buf bytes.Buffer
+	lines := node.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		seg := lines.At(i)
+		buf.Write(seg.Value(source))
+	}
+	return bytes.TrimSpace(buf.Bytes())
+}
+
+// removeGeneratedTOC removes the list markdown-toc generates between
+// <!-- toc --> and <!-- tocstop -->, #contents replaces it.
+// It reports whether the document has a table of contents marker.
+func removeGeneratedTOC(doc ast.Node, source []byte) bool {
+	found := false
+	for node := doc.FirstChild(); node != nil; node = node.NextSibling() {
+		if !isTOCMarker(node, source) {
+			continue
+		}
+		found = true
+		if _, ok := node.(*ast.HTMLBlock); !ok {
+			continue
+		}
+		for next := node.NextSibling(); next != nil; next = next.NextSibling() {
+			if _, ok := next.(*ast.HTMLBlock); ok && tocStopRe.Match(blockText(next, source)) {
+				for node.NextSibling() != next {
+					doc.RemoveChild(doc, node.NextSibling())
+				}
+				doc.RemoveChild(doc, next)
+				break
+			}
+		}
+	}
+	return found
+}
+
+// contentsHeading returns the top-level heading #contents is inserted after,
+// or nil when the document has no more than threshold headings.
+func contentsHeading(doc ast.Node, threshold int) ast.Node {
+	var first ast.Node
+	count := 0
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if _, ok := node.(*ast.Heading); ok && entering {
+			if first == nil && node.Parent() == doc {
+				first = node
+			}
+			count++
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	if count <= threshold {
+		return nil
+	}
+	return first
+}
+
+func writeContents(w util.BufWriter) {
+	_, _ = w.WriteString("#contents\n")
+}
@@ -0,0 +1,40 @@
+package md2pw
+
+import (
+	"fmt"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// Warning reports a construct that PukiWiki cannot represent as it is.
+// The conversion still succeeds and writes the closest notation.
+type Warning struct {
+	Line    int // 1-based line of the construct in the Markdown source, 0 when unknown
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("line %d: %s", w.Line, w.Message)
+}
+
+// warn reports a warning about the node to the warning handler, if any.
+func (r *nodeRenderer) warn(source []byte, node ast.Node, format string, args ...any) {
+	if r.opts.WarningHandler == nil {
+		return
+	}
+	r.opts.WarningHandler(Warning{Line: nodeLine(node, source), Message: fmt.Sprintf(format, args...)})
+}
+
+// nodeLine returns the line the block starts at. Blocks without lines of their
+// own, such as thematic breaks, get the line of their parent.
+func nodeLine(node ast.Node, source []byte) int {
+	for n := node; n != nil; n = n.FirstChild() {
+		if n.Type() == ast.TypeBlock && n.Lines().Len() > 0 {
+			return lineOf(source, n.Lines().At(0).Start)
+		}
+	}
+	if p := node.Parent(); p != nil {
+		return nodeLine(p, source)
+	}
+	return 0
+}